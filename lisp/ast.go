@@ -0,0 +1,303 @@
+package lisp
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// LispValue represents a value
+type LispValue interface {
+	String() string
+}
+
+// LispAtom represents an atomic value (symbol). Atoms read from source keep
+// their position, like lists.
+type LispAtom struct {
+	Value  string
+	Line   int
+	Column int
+}
+
+// String returns the string representation of the atom
+func (a *LispAtom) String() string {
+	return a.Value
+}
+
+// LispNumber represents a numeric value
+type LispNumber struct {
+	Value int
+}
+
+// String returns the string representation of the number
+func (n *LispNumber) String() string {
+	return strconv.Itoa(n.Value)
+}
+
+// LispFloat represents a float value
+type LispFloat struct {
+	Value float64
+}
+
+// String returns the string representation of the float. Integral floats
+// keep a decimal point so they can't be mistaken for exact integers.
+func (f *LispFloat) String() string {
+	switch {
+	case math.IsInf(f.Value, 1):
+		return "+inf.0"
+	case math.IsInf(f.Value, -1):
+		return "-inf.0"
+	case math.IsNaN(f.Value):
+		return "+nan.0"
+	}
+	s := strconv.FormatFloat(f.Value, 'f', -1, 64)
+	if !strings.Contains(s, DOT) {
+		s += ".0"
+	}
+	return s
+}
+
+// LispRational represents an exact ratio of two integers
+type LispRational struct {
+	Value *big.Rat
+}
+
+// String returns the string representation of the rational
+func (r *LispRational) String() string {
+	return r.Value.RatString()
+}
+
+// LispString represents a string value
+type LispString struct {
+	Value string
+}
+
+// String returns the string representation of the string
+func (s *LispString) String() string {
+	return "\"" + s.Value + "\""
+}
+
+// LispList represents a list of Lisp values. Lists read from source keep
+// the position of their opening bracket so errors can point back to them.
+type LispList struct {
+	Elements []LispValue
+	Line     int
+	Column   int
+}
+
+// String returns the string representation of the list
+func (l *LispList) String() string {
+	var sb strings.Builder
+	sb.WriteString(string(OPEN_BRACKET))
+	for i, elem := range l.Elements {
+		sb.WriteString(elem.String())
+		if i < len(l.Elements)-1 {
+			sb.WriteString(EMPTY_STRING)
+		}
+	}
+	sb.WriteString(string(CLOSE_BRACKET))
+	return sb.String()
+}
+
+// LispFunction represents a user-defined function
+type LispFunction struct {
+	Name   *LispAtom
+	Params []LispValue
+	Body   LispValue
+	Env    Environment
+}
+
+// String returns the string representation of the function
+func (f *LispFunction) String() string {
+	if f.Name != nil {
+		return strings.ToUpper(f.Name.Value)
+	}
+	return FUNCTION
+}
+
+// LispPrimitive represents a function implemented in Go, such as the
+// constructors and accessors defined by defstruct
+type LispPrimitive struct {
+	Name string
+	Fn   func(args []LispValue) (LispValue, error)
+}
+
+// String returns the string representation of the primitive
+func (p *LispPrimitive) String() string {
+	return strings.ToUpper(p.Name)
+}
+
+// LispContinuation represents an escaping continuation captured by call/cc.
+// It can only be invoked while the call/cc that captured it is running.
+type LispContinuation struct {
+	Frame  int
+	Active bool
+}
+
+// String returns the string representation of the continuation
+func (k *LispContinuation) String() string {
+	return "#<continuation>"
+}
+
+// LispBoolean represents a boolean value
+type LispBoolean struct {
+	Value bool
+}
+
+// String returns the string representation of the boolean
+func (b *LispBoolean) String() string {
+	if b.Value {
+		return TRUE
+	}
+	return FALSE
+}
+
+// LispRandomState represents the state of a pseudo-random number generator
+type LispRandomState struct {
+	State uint64
+}
+
+// String returns the string representation of the random state
+func (r *LispRandomState) String() string {
+	return "#<random-state>"
+}
+
+// LispParameter represents a parameter object created by make-parameter.
+// Calling it returns its value, which parameterize rebinds dynamically.
+type LispParameter struct {
+	Value     LispValue
+	Converter LispValue
+}
+
+// String returns the string representation of the parameter
+func (p *LispParameter) String() string {
+	return "#<parameter>"
+}
+
+// LispRecordType represents a record type defined by defstruct or define-record-type
+type LispRecordType struct {
+	Name  string
+	Slots []string
+}
+
+// String returns the string representation of the record type
+func (t *LispRecordType) String() string {
+	return fmt.Sprintf("#<record-type %s>", t.Name)
+}
+
+// LispRecord represents an instance of a record type
+type LispRecord struct {
+	Type   *LispRecordType
+	Values []LispValue
+}
+
+// String returns the string representation of the record, which lists the
+// slots as keyword and value pairs
+func (r *LispRecord) String() string {
+	var sb strings.Builder
+	sb.WriteString("#S(")
+	sb.WriteString(r.Type.Name)
+	for i, slot := range r.Type.Slots {
+		sb.WriteString(EMPTY_STRING)
+		sb.WriteString(KEYWORD_PREFIX + slot)
+		sb.WriteString(EMPTY_STRING)
+		sb.WriteString(r.Values[i].String())
+	}
+	sb.WriteString(string(CLOSE_BRACKET))
+	return sb.String()
+}
+
+// LispClass represents a class of the object system. Every value has a
+// class, and classes defined by defclass describe the slots of their instances.
+type LispClass struct {
+	Name   string
+	Supers []*LispClass
+	Slots  []classSlot
+	// Precedence is the class precedence list, from the class itself to t
+	Precedence []*LispClass
+	// Env is the environment slot initforms are evaluated in
+	Env Environment
+}
+
+// String returns the string representation of the class
+func (c *LispClass) String() string {
+	return fmt.Sprintf("#<class %s>", c.Name)
+}
+
+// LispInstance represents an instance of a class defined by defclass
+type LispInstance struct {
+	Class *LispClass
+	Slots map[string]LispValue
+}
+
+// String returns the string representation of the instance
+func (i *LispInstance) String() string {
+	return fmt.Sprintf("#<%s>", i.Class.Name)
+}
+
+// LispGeneric represents a generic function, which dispatches to its
+// methods on the classes of all of its arguments
+type LispGeneric struct {
+	Name    string
+	Params  []LispValue
+	Methods []*genericMethod
+	// Env is the environment the generic function was defined in
+	Env Environment
+}
+
+// String returns the string representation of the generic function
+func (g *LispGeneric) String() string {
+	return fmt.Sprintf("#<generic-function %s>", g.Name)
+}
+
+// LispValues represents the multiple values returned by values. It only
+// lives until a single-value context takes its primary value.
+type LispValues struct {
+	Values []LispValue
+}
+
+// String returns the string representation of the values, one per line
+func (v *LispValues) String() string {
+	lines := make([]string, 0, len(v.Values))
+	for _, val := range v.Values {
+		lines = append(lines, val.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LispCondition represents a condition signalled by error or by a failing builtin
+type LispCondition struct {
+	Type      string
+	Message   string
+	Irritants []LispValue
+	// Slots holds the slot values of conditions of a defined type
+	Slots  map[string]LispValue
+	Line   int
+	Column int
+}
+
+// String returns the string representation of the condition
+func (c *LispCondition) String() string {
+	return fmt.Sprintf("#<condition %s: %s>", c.Type, c.Description())
+}
+
+// Description returns the message of the condition followed by its irritants
+func (c *LispCondition) Description() string {
+	var sb strings.Builder
+	sb.WriteString(c.Message)
+	for _, irritant := range c.Irritants {
+		sb.WriteString(EMPTY_STRING)
+		sb.WriteString(irritant.String())
+	}
+	return sb.String()
+}
+
+// LispNil represents a nil/null value
+type LispNil struct{}
+
+// String returns the string representation of the nil
+func (n *LispNil) String() string {
+	return NIL
+}
@@ -0,0 +1,146 @@
+package lisp
+
+// newBuiltins returns the builtins every interpreter starts with. They are
+// special forms, which evaluate the arguments they need themselves.
+func newBuiltins() map[string]*Builtin {
+	builtins := []*Builtin{
+		{Name: QUOTE, Doc: "returns its argument without evaluating it", Form: builtinQuote, Special: true},
+		{Name: FORMAT, Doc: "format input", Form: builtinFormat},
+		{Name: READ, Doc: "parses the next expression of the current input port", Form: builtinRead, Unsafe: true},
+		{Name: READ_LINE, Doc: "next line of the current input port, or nil at the end", Form: builtinReadLine, Unsafe: true},
+		{Name: READ_CHAR, Doc: "next character of the current input port as a string, or nil at the end", Form: withName(READ_CHAR, builtinReadChar), Unsafe: true},
+		{Name: PEEK_CHAR, Doc: "next character of the current input port, left in the port, or nil at the end", Form: withName(PEEK_CHAR, builtinReadChar), Unsafe: true},
+		{Name: READ_FROM_STRING, Doc: "parses the first expression of a string", Form: builtinReadFromString},
+		{Name: PRINT, Doc: "prints a Lisp value to the console", Form: builtinPrint},
+		{Name: PLUS, Doc: "addition operation", Form: builtinAdd},
+		{Name: MINUS, Doc: "subtraction operation", Form: builtinSub},
+		{Name: STAR, Doc: "multiplication operation", Form: builtinMul},
+		{Name: SLASH, Doc: "division operation", Form: builtinDiv},
+		{Name: PERCENT, Doc: "modulo operation", Form: builtinMod},
+		{Name: POW, Doc: "pow operation", Form: builtinPow},
+		{Name: SQRT, Doc: "sqrt operation", Form: builtinSqrt},
+		{Name: CONCAT, Doc: "concat operation", Form: builtinConcat},
+		{Name: SUBSTRING, Doc: "substring operation", Form: builtinSubstring},
+		{Name: IS_NUMBER, Doc: "isNumber operation", Form: builtinIsNumber},
+		{Name: IS_STRING, Doc: "isString operation", Form: builtinIsString},
+		{Name: LESS_THAN, Doc: "less than condition", Form: builtinLt},
+		{Name: LESS_OR_EQUAL_THAN, Doc: "less or equal than condition", Form: builtinLtOrEq},
+		{Name: GREATER_THAN, Doc: "greater than condition", Form: builtinGt},
+		{Name: GREATER_OR_EQUAL_THAN, Doc: "greater or equal than condition", Form: builtinGtOrEq},
+		{Name: EQUAL, Doc: "equal to condition", Form: builtinEq},
+		{Name: NUM_NOT_EQUAL, Doc: "not equal to condition. It holds when no two arguments are equal.", Form: builtinNumNotEq},
+		{Name: FLOOR, Doc: "floor operation. It rounds towards negative infinity.", Form: withName(FLOOR, builtinRound)},
+		{Name: CEILING, Doc: "ceiling operation. It rounds towards positive infinity.", Form: withName(CEILING, builtinRound)},
+		{Name: ROUND, Doc: "round operation. It rounds to the nearest integer, ties to even.", Form: withName(ROUND, builtinRound)},
+		{Name: TRUNCATE, Doc: "truncate operation. It rounds towards zero.", Form: withName(TRUNCATE, builtinRound)},
+		{Name: ABS, Doc: "absolute value operation", Form: builtinAbs},
+		{Name: MIN, Doc: "minimum of numbers", Form: withName(MIN, builtinMinMax)},
+		{Name: MAX, Doc: "maximum of numbers", Form: withName(MAX, builtinMinMax)},
+		{Name: EXACT, Doc: "exact? predicate. It checks that a number is an integer or a rational.", Form: withName(EXACT, builtinIsExact)},
+		{Name: INEXACT, Doc: "inexact? predicate. It checks that a number is a float.", Form: withName(INEXACT, builtinIsExact)},
+		{Name: SIN, Doc: "sine of an angle in radians", Form: withName(SIN, builtinFloatFunction)},
+		{Name: COS, Doc: "cosine of an angle in radians", Form: withName(COS, builtinFloatFunction)},
+		{Name: TAN, Doc: "tangent of an angle in radians", Form: withName(TAN, builtinFloatFunction)},
+		{Name: ASIN, Doc: "arc sine", Form: withName(ASIN, builtinFloatFunction)},
+		{Name: ACOS, Doc: "arc cosine", Form: withName(ACOS, builtinFloatFunction)},
+		{Name: ATAN, Doc: "arc tangent. With two arguments it behaves like atan2.", Form: withName(ATAN, builtinAtan)},
+		{Name: ATAN2, Doc: "arc tangent of y/x using the signs of both to pick the quadrant", Form: withName(ATAN2, builtinAtan)},
+		{Name: EXP, Doc: "exponential function", Form: withName(EXP, builtinFloatFunction)},
+		{Name: LOG, Doc: "natural logarithm. The optional second argument is the base.", Form: builtinLog},
+		{Name: LOG2, Doc: "base 2 logarithm", Form: withName(LOG2, builtinFloatFunction)},
+		{Name: LOG10, Doc: "base 10 logarithm", Form: withName(LOG10, builtinFloatFunction)},
+		{Name: GCD, Doc: "greatest common divisor of integers", Form: withName(GCD, builtinGcdLcm)},
+		{Name: LCM, Doc: "least common multiple of integers", Form: withName(LCM, builtinGcdLcm)},
+		{Name: QUOTIENT, Doc: "integer division truncated towards zero", Form: withName(QUOTIENT, builtinIntegerDivision)},
+		{Name: REMAINDER, Doc: "remainder of integer division. It has the sign of the dividend.", Form: withName(REMAINDER, builtinIntegerDivision)},
+		{Name: MODULO, Doc: "modulo of integer division. It has the sign of the divisor.", Form: withName(MODULO, builtinIntegerDivision)},
+		{Name: EXPT, Doc: "expt operation, same as pow", Form: builtinPow},
+		{Name: ISQRT, Doc: "integer square root", Form: builtinIsqrt},
+		{Name: LOGAND, Doc: "bitwise and of integers", Form: withName(LOGAND, builtinBitwise)},
+		{Name: LOGIOR, Doc: "bitwise inclusive or of integers", Form: withName(LOGIOR, builtinBitwise)},
+		{Name: LOGXOR, Doc: "bitwise exclusive or of integers", Form: withName(LOGXOR, builtinBitwise)},
+		{Name: LOGNOT, Doc: "bitwise complement of an integer", Form: builtinLognot},
+		{Name: ASH, Doc: "arithmetic shift of an integer", Form: builtinAsh},
+		{Name: RANDOM, Doc: "random number below an integer or float limit", Form: builtinRandom},
+		{Name: RANDOM_SEED, Doc: "reseeds the default random number generator", Form: builtinRandomSeed},
+		{Name: SHUFFLE, Doc: "shuffled copy of a list", Form: builtinShuffle},
+		{Name: RANDOM_CHOICE, Doc: "random element of a list", Form: builtinRandomChoice},
+		{Name: MAKE_RANDOM_STATE, Doc: "creates a random state usable by random, shuffle and random-choice", Form: builtinMakeRandomState},
+		{Name: ERROR, Doc: "signals an error with a message and irritants", Form: builtinError},
+		{Name: HANDLER_CASE, Doc: "evaluates an expression and handles the errors it signals by condition type", Form: builtinHandlerCase, Special: true},
+		{Name: GUARD, Doc: "evaluates a body and handles the errors it signals with cond-like clauses", Form: builtinGuard, Special: true},
+		{Name: IGNORE_ERRORS, Doc: "evaluates forms and returns nil if one of them signals an error", Form: builtinIgnoreErrors, Special: true},
+		{Name: CONDITION_TYPE_OF, Doc: "type of a condition", Form: withName(CONDITION_TYPE_OF, builtinConditionAccessor)},
+		{Name: CONDITION_MESSAGE, Doc: "message of a condition", Form: withName(CONDITION_MESSAGE, builtinConditionAccessor)},
+		{Name: CONDITION_IRRITANTS, Doc: "irritants of a condition", Form: withName(CONDITION_IRRITANTS, builtinConditionAccessor)},
+		{Name: CONDITION_POSITION, Doc: "source line and column where a condition was signalled", Form: withName(CONDITION_POSITION, builtinConditionAccessor)},
+		{Name: IS_ERROR_OBJECT, Doc: "error-object? predicate. It checks that a value is a condition.", Form: withName(IS_ERROR_OBJECT, builtinConditionAccessor)},
+		{Name: ERROR_OBJECT_MESSAGE, Doc: "message of a condition", Form: withName(ERROR_OBJECT_MESSAGE, builtinConditionAccessor)},
+		{Name: ERROR_OBJECT_IRRITANTS, Doc: "irritants of a condition", Form: withName(ERROR_OBJECT_IRRITANTS, builtinConditionAccessor)},
+		{Name: SIGNAL, Doc: "signals a condition and returns nil if no handler takes it", Form: builtinSignal},
+		{Name: WARN, Doc: "signals a warning and prints it unless a handler muffles it", Form: builtinWarn},
+		{Name: HANDLER_BIND, Doc: "evaluates forms with handlers called where conditions are signalled, without unwinding", Form: builtinHandlerBind, Special: true},
+		{Name: RESTART_CASE, Doc: "evaluates an expression with named restarts that handlers can invoke", Form: builtinRestartCase, Special: true},
+		{Name: INVOKE_RESTART, Doc: "transfers control to the innermost active restart with a name", Form: builtinInvokeRestart},
+		{Name: COMPUTE_RESTARTS, Doc: "names of the active restarts, innermost first", Form: builtinComputeRestarts},
+		{Name: MUFFLE_WARNING, Doc: "invokes the muffle-warning restart established by warn", Form: builtinMuffleWarning},
+		{Name: DEFINE_CONDITION, Doc: "defines a condition type with its parent types, slots and report message", Form: builtinDefineCondition, Special: true},
+		{Name: MAKE_CONDITION, Doc: "creates a condition of a defined type from keyword initargs", Form: builtinMakeCondition},
+		{Name: CONDITION_SLOT, Doc: "value of a slot of a condition", Form: builtinConditionSlot},
+		{Name: UNWIND_PROTECT, Doc: "evaluates a form and then cleanup forms, however the form is left", Form: builtinUnwindProtect, Special: true},
+		{Name: DYNAMIC_WIND, Doc: "calls before, body and after thunks, running after however the body is left", Form: builtinDynamicWind},
+		{Name: CATCH, Doc: "evaluates forms and returns the value thrown to a tag, if any", Form: builtinCatch, Special: true},
+		{Name: THROW, Doc: "unwinds to the innermost catch of a tag with a value", Form: builtinThrow},
+		{Name: BLOCK, Doc: "evaluates forms in a named block that return-from can leave", Form: builtinBlock, Special: true},
+		{Name: RETURN_FROM, Doc: "leaves the lexically enclosing block of a name with a value. Functions defined with defun are in a block named after them.", Form: withName(RETURN_FROM, builtinReturnFrom), Special: true},
+		{Name: RETURN, Doc: "leaves the lexically enclosing block named nil with a value", Form: withName(RETURN, builtinReturnFrom)},
+		{Name: CALL_CC, Doc: "calls a function with the current continuation, which escapes back to the call/cc when invoked", Form: builtinCallCC},
+		{Name: CALL_WITH_CURRENT_CONTINUATION, Doc: "same as call/cc", Form: builtinCallCC},
+		{Name: DEFVAR, Doc: "declares a special variable, dynamically rebound by let, and assigns it if unbound", Form: withName(DEFVAR, builtinDefvar), Special: true},
+		{Name: DEFPARAMETER, Doc: "declares a special variable, dynamically rebound by let, and assigns it", Form: withName(DEFPARAMETER, builtinDefvar), Special: true},
+		{Name: MAKE_PARAMETER, Doc: "creates a parameter object with a value and an optional converter", Form: builtinMakeParameter},
+		{Name: PARAMETERIZE, Doc: "evaluates forms with parameter objects rebound for their dynamic extent", Form: builtinParameterize, Special: true},
+		{Name: VALUES, Doc: "returns its arguments as multiple values", Form: builtinValues},
+		{Name: MULTIPLE_VALUE_BIND, Doc: "binds variables to the multiple values of a form and evaluates a body", Form: builtinMultipleValueBind, Special: true},
+		{Name: MULTIPLE_VALUE_LIST, Doc: "list of the multiple values of a form", Form: builtinMultipleValueList},
+		{Name: CALL_WITH_VALUES, Doc: "calls a consumer with the multiple values returned by a producer", Form: builtinCallWithValues},
+		{Name: RECEIVE, Doc: "binds formals to the multiple values of a form and evaluates a body", Form: builtinReceive, Special: true},
+		{Name: FLOOR_DIV, Doc: "floor division of integers. It returns the quotient and the remainder.", Form: withName(FLOOR_DIV, builtinDivision)},
+		{Name: TRUNCATE_DIV, Doc: "truncated division of integers. It returns the quotient and the remainder.", Form: withName(TRUNCATE_DIV, builtinDivision)},
+		{Name: IS_EQUAL, Doc: "equal predicate. It checks that two values are structurally equal, including lists and records.", Form: builtinEqual},
+		{Name: DEFSTRUCT, Doc: "defines a record type with a keyword constructor, accessors, a predicate and a copier", Form: builtinDefstruct, Special: true},
+		{Name: DEFINE_RECORD_TYPE, Doc: "defines a record type with a positional constructor, a predicate, accessors and modifiers", Form: builtinDefineRecordType, Special: true},
+		{Name: DEFCLASS, Doc: "defines a class with superclasses and slots", Form: builtinDefclass, Special: true},
+		{Name: MAKE_INSTANCE, Doc: "creates an instance of a class from keyword initargs", Form: builtinMakeInstance},
+		{Name: SLOT_VALUE, Doc: "value of a slot of an instance", Form: withName(SLOT_VALUE, builtinSlotValue)},
+		{Name: SET_SLOT_VALUE, Doc: "changes the value of a slot of an instance", Form: withName(SET_SLOT_VALUE, builtinSlotValue)},
+		{Name: CLASS_OF, Doc: "class of a value. Builtin types such as numbers, strings and lists have classes too.", Form: withName(CLASS_OF, builtinClassOf)},
+		{Name: FIND_CLASS, Doc: "class with a name", Form: withName(FIND_CLASS, builtinClassOf)},
+		{Name: DEFGENERIC, Doc: "defines a generic function dispatching on the classes of all its arguments", Form: builtinDefgeneric, Special: true},
+		{Name: DEFMETHOD, Doc: "defines a primary, :before, :after or :around method of a generic function", Form: builtinDefmethod, Special: true},
+		{Name: CALL_NEXT_METHOD, Doc: "calls the next most specific method from a primary or :around method", Form: methodOnly(CALL_NEXT_METHOD)},
+		{Name: NEXT_METHOD_P, Doc: "checks that a method has a next method", Form: methodOnly(NEXT_METHOD_P)},
+		{Name: MATCH, Doc: "evaluates the body of the first clause whose pattern matches a value", Form: builtinMatch, Special: true},
+		{Name: DESTRUCTURING_BIND, Doc: "binds the variables of a lambda list to the parts of a list", Form: builtinDestructuringBind, Special: true},
+		{Name: IF, Doc: "if conditional struct", Form: builtinIf, Special: true},
+		{Name: DEFUN, Doc: "function definition", Form: builtinDefun, Special: true},
+		{Name: LAMBDA, Doc: "lambda function definition", Form: builtinLambda, Special: true},
+		{Name: LET, Doc: "let local variable definition", Form: builtinLet, Special: true},
+		{Name: AND, Doc: "and logical operation", Form: builtinAnd, Special: true},
+		{Name: OR, Doc: "or logical operation", Form: builtinOr, Special: true},
+		{Name: NOT, Doc: "not logical operation", Form: builtinNot},
+		{Name: LIST, Doc: "list definition", Form: builtinList},
+		{Name: CAR, Doc: "car list operation. It retrieves first element of a list.", Form: builtinCar},
+		{Name: CDR, Doc: "cdr list operation. It retrieves the rest elements of a list.", Form: builtinCdr},
+		{Name: CONS, Doc: "cons list operation. It add element to a list.", Form: builtinCons},
+		{Name: LENGTH, Doc: "length list operation. It retrieves the length of a list.", Form: builtinLength},
+		{Name: APPEND, Doc: "append list operation. It add a list to another list.", Form: builtinAppend},
+		{Name: HELP, Doc: "description of a builtin, or the names of all the builtins", Form: builtinHelp},
+	}
+	registry := make(map[string]*Builtin, len(builtins))
+	for _, builtin := range builtins {
+		builtin.Arity = -1
+		registry[builtin.Name] = builtin
+	}
+	return registry
+}
@@ -0,0 +1,721 @@
+package lisp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Condition types known to the interpreter
+const (
+	CONDITION_TYPE        = "condition"
+	SIMPLE_CONDITION_TYPE = "simple-condition"
+	ERROR_TYPE            = "error"
+	SIMPLE_ERROR_TYPE     = "simple-error"
+	WARNING_TYPE          = "warning"
+	SIMPLE_WARNING_TYPE   = "simple-warning"
+	MATCH_ERROR_TYPE      = "match-error"
+)
+
+// conditionType describes a condition type and where it sits in the hierarchy
+type conditionType struct {
+	Name    string
+	Parents []string
+	Slots   []conditionSlot
+	Report  string
+}
+
+// conditionSlot describes a slot of a condition type
+type conditionSlot struct {
+	Name     string
+	Initarg  string
+	Initform LispValue
+}
+
+// newConditionTypes returns the definitions of the predefined condition types
+func newConditionTypes() map[string]*conditionType {
+	return map[string]*conditionType{
+		CONDITION_TYPE:        {Name: CONDITION_TYPE},
+		SIMPLE_CONDITION_TYPE: {Name: SIMPLE_CONDITION_TYPE, Parents: []string{CONDITION_TYPE}},
+		ERROR_TYPE:            {Name: ERROR_TYPE, Parents: []string{CONDITION_TYPE}},
+		SIMPLE_ERROR_TYPE:     {Name: SIMPLE_ERROR_TYPE, Parents: []string{ERROR_TYPE}},
+		WARNING_TYPE:          {Name: WARNING_TYPE, Parents: []string{CONDITION_TYPE}},
+		SIMPLE_WARNING_TYPE:   {Name: SIMPLE_WARNING_TYPE, Parents: []string{WARNING_TYPE}},
+		MATCH_ERROR_TYPE:      {Name: MATCH_ERROR_TYPE, Parents: []string{ERROR_TYPE}},
+	}
+}
+
+// conditionIsA reports whether a condition type is the given type or one of its subtypes
+func (interp *Interpreter) conditionIsA(typeName, want string) bool {
+	if typeName == want {
+		return true
+	}
+	if t, ok := interp.conditionTypes[typeName]; ok {
+		for _, parent := range t.Parents {
+			if interp.conditionIsA(parent, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ConditionError carries a signalled condition through evaluation as a Go error
+type ConditionError struct {
+	Condition *LispCondition
+	// Err is the error the condition was made from, if it didn't come from Lisp code
+	Err error
+	// signalled is set once the condition has been offered to the handlers
+	signalled bool
+}
+
+// Error returns the error message
+func (e *ConditionError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	c := e.Condition
+	return fmt.Sprintf("Error at line %d, column %d: %s", c.Line, c.Column, c.Description())
+}
+
+// Unwrap returns the error the condition was made from
+func (e *ConditionError) Unwrap() error {
+	return e.Err
+}
+
+// conditionFromError converts an evaluation error into a condition object
+func conditionFromError(err error) *LispCondition {
+	switch e := err.(type) {
+	case *ConditionError:
+		return e.Condition
+	case *LispError:
+		return &LispCondition{Type: ERROR_TYPE, Message: e.Message, Line: e.Line, Column: e.Column}
+	}
+	return &LispCondition{Type: ERROR_TYPE, Message: err.Error()}
+}
+
+// controlTransfer is implemented by the errors that unwind evaluation to a
+// form further up the stack instead of reporting a failure
+type controlTransfer interface {
+	error
+	controlTransfer()
+}
+
+// isControlTransfer reports whether an error is a control transfer
+func isControlTransfer(err error) bool {
+	_, ok := err.(controlTransfer)
+	return ok
+}
+
+// handlerBinding is a handler established by handler-bind, or by one of the
+// forms that catch conditions: handler-case, guard and ignore-errors
+type handlerBinding struct {
+	Type string
+	// Handler is called with the condition; it is nil for catching forms
+	Handler LispValue
+	// Frame and Clause identify the catching form and clause to unwind to
+	Frame  int
+	Clause int
+}
+
+// handlerExit unwinds to the catching form that accepted a condition
+type handlerExit struct {
+	Frame     int
+	Clause    int
+	Condition *LispCondition
+}
+
+// Error returns the error message
+func (e *handlerExit) Error() string {
+	return "condition handler exited outside of its dynamic extent"
+}
+
+func (e *handlerExit) controlTransfer() {}
+
+// newFrame returns a fresh identifier for a form that can be unwound to
+func (interp *Interpreter) newFrame() int {
+	interp.frameCounter++
+	return interp.frameCounter
+}
+
+// withHandlers evaluates body with a cluster of handlers established
+func (interp *Interpreter) withHandlers(cluster []handlerBinding, body func() (LispValue, error)) (LispValue, error) {
+	saved := interp.handlerClusters
+	interp.handlerClusters = append(saved[:len(saved):len(saved)], cluster)
+	defer func() { interp.handlerClusters = saved }()
+	return body()
+}
+
+// signalCondition offers a condition to the active handlers, innermost first.
+// A handler runs with only the handlers outside its own cluster active, and
+// declines by returning normally. The result is the control transfer or the
+// error coming out of a handler, or nil when every handler declined.
+func (interp *Interpreter) signalCondition(condition *LispCondition) error {
+	clusters := interp.handlerClusters
+	defer func() { interp.handlerClusters = clusters }()
+	for i := len(clusters) - 1; i >= 0; i-- {
+		for _, binding := range clusters[i] {
+			if !interp.conditionIsA(condition.Type, binding.Type) {
+				continue
+			}
+			if binding.Handler == nil {
+				return &handlerExit{Frame: binding.Frame, Clause: binding.Clause, Condition: condition}
+			}
+			interp.handlerClusters = clusters[:i:i]
+			if _, err := applyFunction(binding.Handler, []LispValue{condition}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// signalError signals the condition behind an evaluation error, unless it
+// was signalled already. Errors are signalled by the innermost form they
+// pass through, so handlers run before anything is unwound.
+func (interp *Interpreter) signalError(err error) error {
+	if err == nil || isControlTransfer(err) {
+		return err
+	}
+	condErr, ok := err.(*ConditionError)
+	if !ok {
+		condErr = &ConditionError{Condition: conditionFromError(err), Err: err}
+	}
+	if condErr.signalled {
+		return condErr
+	}
+	condErr.signalled = true
+	if transfer := interp.signalCondition(condErr.Condition); transfer != nil {
+		return transfer
+	}
+	if interp.Debugger != nil {
+		if transfer := interp.Debugger(condErr.Condition); transfer != nil {
+			return transfer
+		}
+	}
+	return condErr
+}
+
+// makeCondition creates a condition from the arguments of error, signal or
+// warn: a condition object, a message string followed by irritants, or a
+// condition type name followed by initargs
+func makeCondition(env Environment, vals []LispValue, simpleType string) (*LispCondition, error) {
+	interp := interpreterOf(env)
+	switch v := vals[0].(type) {
+	case *LispCondition:
+		return v, nil
+	case *LispString:
+		return &LispCondition{Type: simpleType, Message: v.Value, Irritants: vals[1:]}, nil
+	case *LispAtom:
+		if _, ok := interp.conditionTypes[v.Value]; ok {
+			return instantiateCondition(env, v.Value, vals[1:])
+		}
+	}
+	return &LispCondition{Type: simpleType, Message: vals[0].String(), Irritants: vals[1:]}, nil
+}
+
+// instantiateCondition creates a condition of a defined type, filling its
+// slots from keyword initargs or from the slot initforms
+func instantiateCondition(env Environment, typeName string, initargs []LispValue) (*LispCondition, error) {
+	interp := interpreterOf(env)
+	if len(initargs)%2 != 0 {
+		return nil, fmt.Errorf("odd number of initargs for condition %s", typeName)
+	}
+	condition := &LispCondition{Type: typeName, Slots: map[string]LispValue{}}
+	var visit func(name string) error
+	visit = func(name string) error {
+		t := interp.conditionTypes[name]
+		if t == nil {
+			return nil
+		}
+		if condition.Message == "" {
+			condition.Message = t.Report
+		}
+		for _, slot := range t.Slots {
+			if _, done := condition.Slots[slot.Name]; done {
+				continue
+			}
+			var value LispValue = &LispNil{}
+			if slot.Initform != nil {
+				val, err := Eval(env, slot.Initform)
+				if err != nil {
+					return err
+				}
+				value = val
+			}
+			for i := 0; i < len(initargs); i += 2 {
+				if key, ok := initargs[i].(*LispAtom); ok && key.Value == slot.Initarg {
+					value = initargs[i+1]
+					break
+				}
+			}
+			condition.Slots[slot.Name] = value
+		}
+		for _, parent := range t.Parents {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := visit(typeName); err != nil {
+		return nil, err
+	}
+	if condition.Message == "" {
+		condition.Message = fmt.Sprintf("condition of type %s", typeName)
+	}
+	return condition, nil
+}
+
+// evalArgs evaluates every argument of a builtin
+func evalArgs(env Environment, args []LispValue) ([]LispValue, error) {
+	vals := make([]LispValue, 0, len(args))
+	for _, arg := range args {
+		val, err := Eval(env, arg)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, val)
+	}
+	return vals, nil
+}
+
+// builtinError is built-in implementation of error operation. It signals a
+// simple-error with a message and irritants, a condition of a defined type
+// with initargs, or a condition object again.
+func builtinError(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 1 {
+		return nil, &LispError{Message: "wrong number of arguments to error", Line: 0, Column: 0}
+	}
+	vals, err := evalArgs(env, args)
+	if err != nil {
+		return nil, err
+	}
+	condition, err := makeCondition(env, vals, SIMPLE_ERROR_TYPE)
+	if err != nil {
+		return nil, err
+	}
+	return nil, &ConditionError{Condition: condition}
+}
+
+// builtinSignal is built-in implementation of signal operation. It offers a
+// condition to the active handlers and returns nil if they all decline.
+func builtinSignal(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) < 1 {
+		return nil, &LispError{Message: "wrong number of arguments to signal", Line: 0, Column: 0}
+	}
+	vals, err := evalArgs(env, args)
+	if err != nil {
+		return nil, err
+	}
+	condition, err := makeCondition(env, vals, SIMPLE_CONDITION_TYPE)
+	if err != nil {
+		return nil, err
+	}
+	if err := interp.signalCondition(condition); err != nil {
+		return nil, err
+	}
+	return &LispNil{}, nil
+}
+
+// builtinWarn is built-in implementation of warn operation. It signals a
+// warning with a muffle-warning restart active, and prints the warning to
+// stderr unless a handler muffles it.
+func builtinWarn(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) < 1 {
+		return nil, &LispError{Message: "wrong number of arguments to warn", Line: 0, Column: 0}
+	}
+	vals, err := evalArgs(env, args)
+	if err != nil {
+		return nil, err
+	}
+	condition, err := makeCondition(env, vals, SIMPLE_WARNING_TYPE)
+	if err != nil {
+		return nil, err
+	}
+	if !interp.conditionIsA(condition.Type, WARNING_TYPE) {
+		return nil, fmt.Errorf("invalid argument to warn: %s is not a warning", condition.Type)
+	}
+	frame := interp.newFrame()
+	restarts := []restartPoint{{Name: MUFFLE_WARNING, Frame: frame, Report: "Ignore the warning"}}
+	_, err = interp.withRestarts(restarts, func() (LispValue, error) {
+		return nil, interp.signalCondition(condition)
+	})
+	if invocation, ok := err.(*restartInvocation); ok && invocation.Frame == frame {
+		return &LispNil{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintln(interp.errorOutput(), "Warning:", condition.Description())
+	return &LispNil{}, nil
+}
+
+// builtinMakeCondition is built-in implementation of make-condition operation
+func builtinMakeCondition(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) < 1 {
+		return nil, &LispError{Message: "wrong number of arguments to make-condition", Line: 0, Column: 0}
+	}
+	vals, err := evalArgs(env, args)
+	if err != nil {
+		return nil, err
+	}
+	typeName, ok := vals[0].(*LispAtom)
+	if !ok || interp.conditionTypes[typeName.Value] == nil {
+		return nil, fmt.Errorf("unknown condition type: %v", vals[0])
+	}
+	return instantiateCondition(env, typeName.Value, vals[1:])
+}
+
+// builtinDefineCondition is built-in implementation of define-condition. It
+// defines a condition type with its parent types, slots and report message,
+// and defines a reader function for each slot that names one.
+//
+//	(define-condition name (parent...) (slot | (slot :initarg :key :initform form :reader fn)...) [(:report "message")])
+func builtinDefineCondition(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) < 2 {
+		return nil, fmt.Errorf("wrong number of arguments to define-condition")
+	}
+	name, ok := args[0].(*LispAtom)
+	if !ok {
+		return nil, fmt.Errorf("invalid condition name: %v", args[0])
+	}
+	parentList, ok := args[1].(*LispList)
+	if !ok {
+		return nil, fmt.Errorf("invalid condition parents: %v", args[1])
+	}
+	t := &conditionType{Name: name.Value}
+	for _, parent := range parentList.Elements {
+		parentName, ok := parent.(*LispAtom)
+		if !ok || interp.conditionTypes[parentName.Value] == nil {
+			return nil, fmt.Errorf("unknown condition type: %v", parent)
+		}
+		t.Parents = append(t.Parents, parentName.Value)
+	}
+	if len(t.Parents) == 0 {
+		t.Parents = []string{CONDITION_TYPE}
+	}
+
+	readers := map[string]string{}
+	if len(args) > 2 {
+		slotList, ok := args[2].(*LispList)
+		if !ok {
+			return nil, fmt.Errorf("invalid condition slots: %v", args[2])
+		}
+		for _, spec := range slotList.Elements {
+			slot, reader, err := parseConditionSlot(spec)
+			if err != nil {
+				return nil, err
+			}
+			t.Slots = append(t.Slots, slot)
+			if reader != "" {
+				readers[reader] = slot.Name
+			}
+		}
+	}
+	for _, option := range args[min(len(args), 3):] {
+		optionList, ok := option.(*LispList)
+		if !ok || len(optionList.Elements) != 2 {
+			return nil, fmt.Errorf("invalid define-condition option: %v", option)
+		}
+		key, ok := optionList.Elements[0].(*LispAtom)
+		report, isString := optionList.Elements[1].(*LispString)
+		if !ok || key.Value != REPORT_KEYWORD || !isString {
+			return nil, fmt.Errorf("invalid define-condition option: %v", option)
+		}
+		t.Report = report.Value
+	}
+
+	interp.conditionTypes[name.Value] = t
+	for reader, slotName := range readers {
+		param := &LispAtom{Value: "condition"}
+		body := &LispList{Elements: []LispValue{
+			&LispAtom{Value: CONDITION_SLOT},
+			param,
+			&LispList{Elements: []LispValue{&LispAtom{Value: QUOTE}, &LispAtom{Value: slotName}}},
+		}}
+		env[reader] = &LispFunction{Name: &LispAtom{Value: reader}, Params: []LispValue{param}, Body: body, Env: env}
+	}
+	return name, nil
+}
+
+// parseConditionSlot parses a define-condition slot specification and
+// returns the slot with the name of its reader, if any
+func parseConditionSlot(spec LispValue) (conditionSlot, string, error) {
+	if atom, ok := spec.(*LispAtom); ok {
+		return conditionSlot{Name: atom.Value, Initarg: KEYWORD_PREFIX + atom.Value}, "", nil
+	}
+	list, ok := spec.(*LispList)
+	if !ok || len(list.Elements)%2 != 1 {
+		return conditionSlot{}, "", fmt.Errorf("invalid condition slot: %v", spec)
+	}
+	name, ok := list.Elements[0].(*LispAtom)
+	if !ok {
+		return conditionSlot{}, "", fmt.Errorf("invalid condition slot: %v", spec)
+	}
+	slot := conditionSlot{Name: name.Value, Initarg: KEYWORD_PREFIX + name.Value}
+	reader := ""
+	for i := 1; i < len(list.Elements); i += 2 {
+		key, ok := list.Elements[i].(*LispAtom)
+		if !ok {
+			return conditionSlot{}, "", fmt.Errorf("invalid condition slot option: %v", list.Elements[i])
+		}
+		value := list.Elements[i+1]
+		switch key.Value {
+		case INITARG_KEYWORD:
+			slot.Initarg = value.String()
+		case INITFORM_KEYWORD:
+			slot.Initform = value
+		case READER_KEYWORD, ACCESSOR_KEYWORD:
+			reader = value.String()
+		default:
+			return conditionSlot{}, "", fmt.Errorf("invalid condition slot option: %v", key)
+		}
+	}
+	return slot, reader, nil
+}
+
+// parseHandlerClauses parses handler-case clauses of the form (type ([var]) form...)
+func parseHandlerClauses(clauses []LispValue) ([]handlerBinding, error) {
+	bindings := make([]handlerBinding, 0, len(clauses))
+	for i, clause := range clauses {
+		clauseList, ok := clause.(*LispList)
+		if !ok || len(clauseList.Elements) < 2 {
+			return nil, fmt.Errorf("invalid handler-case clause: %v", clause)
+		}
+		typeName, ok := clauseList.Elements[0].(*LispAtom)
+		if !ok {
+			return nil, fmt.Errorf("invalid handler-case condition type: %v", clauseList.Elements[0])
+		}
+		params, ok := clauseList.Elements[1].(*LispList)
+		if !ok || len(params.Elements) > 1 {
+			return nil, fmt.Errorf("invalid handler-case clause variable: %v", clauseList.Elements[1])
+		}
+		if len(params.Elements) == 1 {
+			if _, ok := params.Elements[0].(*LispAtom); !ok {
+				return nil, fmt.Errorf("invalid handler-case clause variable: %v", params.Elements[0])
+			}
+		}
+		bindings = append(bindings, handlerBinding{Type: typeName.Value, Clause: i})
+	}
+	return bindings, nil
+}
+
+// builtinHandlerCase is built-in implementation of handler-case. It evaluates
+// an expression and, when it signals a condition, unwinds to the first clause
+// whose condition type matches and runs it with the condition bound to the
+// clause variable.
+//
+//	(handler-case expression (type ([var]) form...)...)
+func builtinHandlerCase(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) < 1 {
+		return nil, fmt.Errorf("wrong number of arguments to handler-case")
+	}
+	cluster, err := parseHandlerClauses(args[1:])
+	if err != nil {
+		return nil, err
+	}
+	frame := interp.newFrame()
+	for i := range cluster {
+		cluster[i].Frame = frame
+	}
+	result, err := interp.withHandlers(cluster, func() (LispValue, error) {
+		return Eval(env, args[0])
+	})
+	if err == nil {
+		return result, nil
+	}
+
+	clauseIndex, condition := -1, (*LispCondition)(nil)
+	if exit, ok := err.(*handlerExit); ok && exit.Frame == frame {
+		clauseIndex, condition = exit.Clause, exit.Condition
+	} else if !isControlTransfer(err) {
+		// the error was not signalled yet, as with an unbound symbol
+		condition = conditionFromError(err)
+		for i, binding := range cluster {
+			if interp.conditionIsA(condition.Type, binding.Type) {
+				clauseIndex = i
+				break
+			}
+		}
+	}
+	if clauseIndex < 0 {
+		return nil, err
+	}
+
+	clause := args[1+clauseIndex].(*LispList)
+	localEnv := newLocalEnvironment(env)
+	if params := clause.Elements[1].(*LispList); len(params.Elements) == 1 {
+		localEnv[params.Elements[0].(*LispAtom).Value] = condition
+	}
+	return evalBody(localEnv, clause.Elements[2:])
+}
+
+// builtinHandlerBind is built-in implementation of handler-bind. It evaluates
+// a body with handlers that are called where a condition is signalled,
+// without unwinding. A handler declines by returning normally. It is a
+// function, or a symbol naming a function or a builtin.
+//
+//	(handler-bind ((type handler)...) form...)
+func builtinHandlerBind(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) < 1 {
+		return nil, fmt.Errorf("wrong number of arguments to handler-bind")
+	}
+	bindings, ok := args[0].(*LispList)
+	if !ok {
+		return nil, fmt.Errorf("invalid handler-bind bindings: %v", args[0])
+	}
+	cluster := make([]handlerBinding, 0, len(bindings.Elements))
+	for _, binding := range bindings.Elements {
+		bindList, ok := binding.(*LispList)
+		if !ok || len(bindList.Elements) != 2 {
+			return nil, fmt.Errorf("invalid handler-bind binding: %v", binding)
+		}
+		typeName, ok := bindList.Elements[0].(*LispAtom)
+		if !ok {
+			return nil, fmt.Errorf("invalid handler-bind condition type: %v", bindList.Elements[0])
+		}
+		handler, err := Eval(env, bindList.Elements[1])
+		if err != nil {
+			return nil, err
+		}
+		handler, ok = functionDesignator(env, handler)
+		if !ok {
+			return nil, fmt.Errorf("invalid handler-bind handler: %v", bindList.Elements[1])
+		}
+		cluster = append(cluster, handlerBinding{Type: typeName.Value, Handler: handler})
+	}
+	return interp.withHandlers(cluster, func() (LispValue, error) {
+		return evalBody(env, args[1:])
+	})
+}
+
+// builtinGuard is built-in implementation of guard. When the body signals a
+// condition, the stack unwinds to the guard, the condition is bound to the
+// variable and the clauses are tried like cond clauses. The condition is
+// signalled again if no clause applies.
+//
+//	(guard (var (test form...)... [(else form...)]) body...)
+func builtinGuard(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) < 1 {
+		return nil, fmt.Errorf("wrong number of arguments to guard")
+	}
+	spec, ok := args[0].(*LispList)
+	if !ok || len(spec.Elements) < 1 {
+		return nil, fmt.Errorf("invalid guard specification: %v", args[0])
+	}
+	variable, ok := spec.Elements[0].(*LispAtom)
+	if !ok {
+		return nil, fmt.Errorf("invalid guard variable: %v", spec.Elements[0])
+	}
+	frame := interp.newFrame()
+	result, err := interp.withHandlers([]handlerBinding{{Type: CONDITION_TYPE, Frame: frame}}, func() (LispValue, error) {
+		return evalBody(env, args[1:])
+	})
+	if err == nil {
+		return result, nil
+	}
+	var condition *LispCondition
+	if exit, ok := err.(*handlerExit); ok && exit.Frame == frame {
+		condition = exit.Condition
+	} else if !isControlTransfer(err) {
+		condition = conditionFromError(err)
+	} else {
+		return nil, err
+	}
+
+	localEnv := newLocalEnvironment(env)
+	localEnv[variable.Value] = condition
+	for _, clause := range spec.Elements[1:] {
+		clauseList, ok := clause.(*LispList)
+		if !ok || len(clauseList.Elements) < 1 {
+			return nil, fmt.Errorf("invalid guard clause: %v", clause)
+		}
+		if atom, ok := clauseList.Elements[0].(*LispAtom); ok && atom.Value == ELSE {
+			return evalBody(localEnv, clauseList.Elements[1:])
+		}
+		test, testErr := Eval(localEnv, clauseList.Elements[0])
+		if testErr != nil {
+			return nil, testErr
+		}
+		if isTrue(test) {
+			if len(clauseList.Elements) == 1 {
+				return test, nil
+			}
+			return evalBody(localEnv, clauseList.Elements[1:])
+		}
+	}
+	return nil, &ConditionError{Condition: condition}
+}
+
+// builtinIgnoreErrors is built-in implementation of ignore-errors. It
+// evaluates its forms and returns nil instead of signalling an error.
+func builtinIgnoreErrors(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	frame := interp.newFrame()
+	result, err := interp.withHandlers([]handlerBinding{{Type: ERROR_TYPE, Frame: frame}}, func() (LispValue, error) {
+		return evalBody(env, args)
+	})
+	if exit, ok := err.(*handlerExit); ok && exit.Frame == frame {
+		return &LispNil{}, nil
+	}
+	if err != nil && !isControlTransfer(err) && interp.conditionIsA(conditionFromError(err).Type, ERROR_TYPE) {
+		return &LispNil{}, nil
+	}
+	return result, err
+}
+
+// builtinConditionAccessor is built-in implementation of the condition accessors and of error-object?
+func builtinConditionAccessor(env Environment, args []LispValue, name string) (LispValue, error) {
+	if len(args) != 1 {
+		return nil, &LispError{Message: fmt.Sprintf("wrong number of arguments to %s", name), Line: 0, Column: 0}
+	}
+	val, err := Eval(env, args[0])
+	if err != nil {
+		return nil, err
+	}
+	condition, ok := val.(*LispCondition)
+	if name == IS_ERROR_OBJECT {
+		return &LispBoolean{Value: ok}, nil
+	}
+	if !ok {
+		return nil, &LispError{Message: fmt.Sprintf("invalid argument to %s: %v is not a condition", name, val), Line: 0, Column: 0}
+	}
+	switch name {
+	case CONDITION_TYPE_OF:
+		return &LispAtom{Value: condition.Type}, nil
+	case CONDITION_MESSAGE, ERROR_OBJECT_MESSAGE:
+		return &LispString{Value: condition.Message}, nil
+	case CONDITION_IRRITANTS, ERROR_OBJECT_IRRITANTS:
+		return &LispList{Elements: append([]LispValue{}, condition.Irritants...)}, nil
+	default:
+		return &LispList{Elements: []LispValue{&LispNumber{Value: condition.Line}, &LispNumber{Value: condition.Column}}}, nil
+	}
+}
+
+// builtinConditionSlot is built-in implementation of condition-slot operation. It reads a slot of a condition.
+func builtinConditionSlot(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) != 2 {
+		return nil, &LispError{Message: "wrong number of arguments to condition-slot", Line: 0, Column: 0}
+	}
+	vals, err := evalArgs(env, args)
+	if err != nil {
+		return nil, err
+	}
+	condition, ok := vals[0].(*LispCondition)
+	if !ok {
+		return nil, fmt.Errorf("invalid argument to condition-slot: %v is not a condition", vals[0])
+	}
+	slotName := strings.TrimPrefix(vals[1].String(), KEYWORD_PREFIX)
+	value, ok := condition.Slots[slotName]
+	if !ok {
+		return nil, fmt.Errorf("condition of type %s has no slot %s", condition.Type, slotName)
+	}
+	return value, nil
+}
@@ -0,0 +1,318 @@
+package lisp
+
+import (
+	"fmt"
+)
+
+// builtinUnwindProtect is built-in implementation of unwind-protect. The
+// cleanup forms run however the protected form is left: by returning, by an
+// error, by a control transfer such as an invoked restart, or by a Go panic.
+// An error in the cleanup forms replaces the outcome of the protected form.
+// The cleanup forms run even when the evaluation was cancelled.
+//
+//	(unwind-protect protected-form cleanup-form...)
+func builtinUnwindProtect(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("wrong number of arguments to unwind-protect")
+	}
+	interp := interpreterOf(env)
+	cleanup := func() (LispValue, error) {
+		return evalBody(env, args[1:])
+	}
+	returned := false
+	defer func() {
+		if !returned {
+			// the protected form panicked, which keeps unwinding afterwards
+			interp.detached(cleanup)
+		}
+	}()
+	result, err := evalValues(env, args[0])
+	returned = true
+	if _, cleanupErr := interp.detached(cleanup); cleanupErr != nil {
+		return nil, cleanupErr
+	}
+	return result, err
+}
+
+// builtinDynamicWind is built-in implementation of dynamic-wind. It calls the
+// before thunk, then the body thunk, then the after thunk, which runs however
+// the body is left or cancelled, like the cleanup forms of unwind-protect.
+// Each thunk is a function of no arguments, or a symbol naming one.
+//
+//	(dynamic-wind before thunk after)
+func builtinDynamicWind(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) != 3 {
+		return nil, fmt.Errorf("wrong number of arguments to dynamic-wind")
+	}
+	thunks, err := evalArgs(env, args)
+	if err != nil {
+		return nil, err
+	}
+	for i, thunk := range thunks {
+		fn, ok := functionDesignator(env, thunk)
+		if !ok {
+			return nil, fmt.Errorf("invalid argument to dynamic-wind: %v is not a function", thunk)
+		}
+		thunks[i] = fn
+	}
+	before, thunk, after := thunks[0], thunks[1], thunks[2]
+	if _, err := applyFunction(before, nil); err != nil {
+		return nil, err
+	}
+	interp := interpreterOf(env)
+	cleanup := func() (LispValue, error) {
+		return applyFunction(after, nil)
+	}
+	returned := false
+	defer func() {
+		if !returned {
+			interp.detached(cleanup)
+		}
+	}()
+	result, err := applyFunction(thunk, nil)
+	returned = true
+	if _, afterErr := interp.detached(cleanup); afterErr != nil {
+		return nil, afterErr
+	}
+	return result, err
+}
+
+// catchPoint is a catch tag established by catch
+type catchPoint struct {
+	Tag   LispValue
+	Frame int
+}
+
+// throwTransfer unwinds to the catch that established a thrown tag
+type throwTransfer struct {
+	Frame int
+	Value LispValue
+}
+
+// Error returns the error message
+func (e *throwTransfer) Error() string {
+	return "throw outside of the dynamic extent of its catch"
+}
+
+func (e *throwTransfer) controlTransfer() {}
+
+// catchTagMatches reports whether a thrown tag is the tag of a catch. Symbols
+// and integers match by value and any other tag only matches itself.
+func catchTagMatches(thrown, caught LispValue) bool {
+	switch t := thrown.(type) {
+	case *LispAtom:
+		c, ok := caught.(*LispAtom)
+		return ok && c.Value == t.Value
+	case *LispNumber:
+		c, ok := caught.(*LispNumber)
+		return ok && c.Value == t.Value
+	}
+	return thrown == caught
+}
+
+// builtinCatch is built-in implementation of catch. It evaluates the body
+// with a tag established, and returns the value thrown to the tag if the
+// body throws to it.
+//
+//	(catch tag form...)
+func builtinCatch(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) < 1 {
+		return nil, fmt.Errorf("wrong number of arguments to catch")
+	}
+	tag, err := Eval(env, args[0])
+	if err != nil {
+		return nil, err
+	}
+	frame := interp.newFrame()
+	saved := interp.catchStack
+	interp.catchStack = append(saved[:len(saved):len(saved)], catchPoint{Tag: tag, Frame: frame})
+	defer func() { interp.catchStack = saved }()
+	result, err := evalBody(env, args[1:])
+	if transfer, ok := err.(*throwTransfer); ok && transfer.Frame == frame {
+		return transfer.Value, nil
+	}
+	return result, err
+}
+
+// builtinThrow is built-in implementation of throw. It unwinds to the
+// innermost catch of the tag, which returns the value.
+//
+//	(throw tag value)
+func builtinThrow(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) != 2 {
+		return nil, fmt.Errorf("wrong number of arguments to throw")
+	}
+	tag, err := Eval(env, args[0])
+	if err != nil {
+		return nil, err
+	}
+	value, err := evalValues(env, args[1])
+	if err != nil {
+		return nil, err
+	}
+	for i := len(interp.catchStack) - 1; i >= 0; i-- {
+		if catchTagMatches(tag, interp.catchStack[i].Tag) {
+			return nil, &throwTransfer{Frame: interp.catchStack[i].Frame, Value: value}
+		}
+	}
+	return nil, fmt.Errorf("no catch for tag: %v", tag)
+}
+
+// blockTag is the lexical binding of a block name. It is only valid while
+// the block is being evaluated.
+type blockTag struct {
+	Name   string
+	Frame  int
+	active bool
+}
+
+// String returns the string representation of the block
+func (b *blockTag) String() string {
+	return fmt.Sprintf("#<block %s>", b.Name)
+}
+
+// blockExit unwinds to the block a return-from names
+type blockExit struct {
+	Frame int
+	Value LispValue
+}
+
+// Error returns the error message
+func (e *blockExit) Error() string {
+	return "return-from outside of the dynamic extent of its block"
+}
+
+func (e *blockExit) controlTransfer() {}
+
+// blockKey is the environment key of the binding of a block name. The
+// prefix keeps it apart from variables.
+func blockKey(name string) string {
+	return "%block:" + name
+}
+
+// blockName returns the name of a block, which is a symbol or nil
+func blockName(val LispValue) (string, bool) {
+	switch v := val.(type) {
+	case *LispAtom:
+		return v.Value, true
+	case *LispNil:
+		return NIL, true
+	}
+	return "", false
+}
+
+// evalBlock evaluates forms in env inside a block, binding the block name in
+// env. It is also used by functions defined with defun, whose bodies are
+// implicitly in a block named after the function.
+func evalBlock(env Environment, name string, body []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	tag := &blockTag{Name: name, Frame: interp.newFrame(), active: true}
+	env[blockKey(name)] = tag
+	result, err := evalBody(env, body)
+	tag.active = false
+	if exit, ok := err.(*blockExit); ok && exit.Frame == tag.Frame {
+		return exit.Value, nil
+	}
+	return result, err
+}
+
+// builtinBlock is built-in implementation of block. return-from the block
+// name in the lexical scope of the body leaves the block with a value.
+//
+//	(block name form...)
+func builtinBlock(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("wrong number of arguments to block")
+	}
+	name, ok := blockName(args[0])
+	if !ok {
+		return nil, fmt.Errorf("invalid block name: %v", args[0])
+	}
+	return evalBlock(newLocalEnvironment(env), name, args[1:])
+}
+
+// builtinReturnFrom is built-in implementation of return-from and return.
+// return leaves the block named nil.
+//
+//	(return-from name [value])
+//	(return [value])
+func builtinReturnFrom(env Environment, args []LispValue, name string) (LispValue, error) {
+	if name == RETURN {
+		args = append([]LispValue{&LispNil{}}, args...)
+	}
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("wrong number of arguments to %s", name)
+	}
+	block, ok := blockName(args[0])
+	if !ok {
+		return nil, fmt.Errorf("invalid block name: %v", args[0])
+	}
+	tag, ok := env[blockKey(block)].(*blockTag)
+	if !ok {
+		return nil, fmt.Errorf("unknown block: %s", block)
+	}
+	if !tag.active {
+		return nil, fmt.Errorf("block %s has already exited", block)
+	}
+	var value LispValue = &LispNil{}
+	if len(args) == 2 {
+		val, err := evalValues(env, args[1])
+		if err != nil {
+			return nil, err
+		}
+		value = val
+	}
+	return nil, &blockExit{Frame: tag.Frame, Value: value}
+}
+
+// continuationInvocation unwinds to the call/cc that captured a continuation
+type continuationInvocation struct {
+	Frame int
+	Value LispValue
+}
+
+// Error returns the error message
+func (e *continuationInvocation) Error() string {
+	return "continuation invoked outside of the dynamic extent of its call/cc"
+}
+
+func (e *continuationInvocation) controlTransfer() {}
+
+// invokeContinuation returns the control transfer to the call/cc that captured a continuation.
+// The call/cc returns the arguments of the continuation as multiple values. Continuations are escape-only, so one can't be invoked once its call/cc has returned.
+func invokeContinuation(k *LispContinuation, vals []LispValue) (LispValue, error) {
+	if !k.Active {
+		return nil, fmt.Errorf("continuation can't be re-entered once its call/cc has returned")
+	}
+	var value LispValue = &LispValues{Values: vals}
+	if len(vals) == 1 {
+		value = vals[0]
+	}
+	return nil, &continuationInvocation{Frame: k.Frame, Value: value}
+}
+
+// builtinCallCC is built-in implementation of call/cc. It calls a function
+// with the current continuation; invoking the continuation makes call/cc
+// return its argument. Continuations only escape: they are valid while the
+// call/cc is running, and unwind through unwind-protect and dynamic-wind.
+//
+//	(call/cc (lambda (k) form))
+func builtinCallCC(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments to call/cc")
+	}
+	fn, err := Eval(env, args[0])
+	if err != nil {
+		return nil, err
+	}
+	k := &LispContinuation{Frame: interp.newFrame(), Active: true}
+	defer func() { k.Active = false }()
+	result, err := applyFunction(fn, []LispValue{k})
+	if invocation, ok := err.(*continuationInvocation); ok && invocation.Frame == k.Frame {
+		return invocation.Value, nil
+	}
+	return result, err
+}
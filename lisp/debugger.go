@@ -0,0 +1,79 @@
+package lisp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// debuggerAbort unwinds to the top level when the user picks abort in the debugger
+type debuggerAbort struct{}
+
+// Error returns the error message
+func (e *debuggerAbort) Error() string {
+	return "aborted to top level"
+}
+
+func (e *debuggerAbort) controlTransfer() {}
+
+// ErrAborted is returned by the evaluation the user aborted in the interactive debugger
+var ErrAborted error = &debuggerAbort{}
+
+// InteractiveDebugger can be used as the Debugger of an interpreter. It
+// offers the active restarts on the current input and output ports and
+// returns the control transfer to the one the user picks, after asking for a
+// value for each of its parameters.
+func (interp *Interpreter) InteractiveDebugger(condition *LispCondition) error {
+	restarts := interp.activeRestarts()
+	if len(restarts) == 0 {
+		return nil
+	}
+	out := interp.output()
+	fmt.Fprintln(out, "Error:", condition.Description())
+	fmt.Fprintln(out, "Restarts:")
+	fmt.Fprintln(out, "  0: [abort] Return to top level")
+	for i, restart := range restarts {
+		fmt.Fprintf(out, "  %d: [%s] %s\n", i+1, restart.Name, restart.Report)
+	}
+
+	in := interp.input()
+	for {
+		fmt.Fprint(out, "Restart number: ")
+		line, ok := readLine(in)
+		if !ok {
+			return ErrAborted
+		}
+		choice, err := strconv.Atoi(strings.TrimSpace(line))
+		if err != nil || choice < 0 || choice > len(restarts) {
+			fmt.Fprintln(out, "Invalid restart number")
+			continue
+		}
+		if choice == 0 {
+			return ErrAborted
+		}
+		restart := restarts[choice-1]
+		args := make([]LispValue, 0, len(restart.Params))
+		for _, param := range restart.Params {
+			fmt.Fprintf(out, "Value for %v: ", param)
+			line, ok := readLine(in)
+			if !ok {
+				return ErrAborted
+			}
+			tokens, err := Lex(line)
+			if err != nil {
+				return err
+			}
+			expr, _, err := Parse(tokens)
+			if err != nil {
+				return err
+			}
+			// the handlers and restarts are still those of the failing form
+			val, err := Eval(interp.env, expr)
+			if err != nil {
+				return err
+			}
+			args = append(args, val)
+		}
+		return invokeRestart(restart, args)
+	}
+}
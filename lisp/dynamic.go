@@ -0,0 +1,164 @@
+package lisp
+
+import (
+	"fmt"
+)
+
+// lookupSpecial returns the current value of a special variable. The second
+// result is false if the name isn't special, and the error is set if it is
+// special but unbound.
+func (interp *Interpreter) lookupSpecial(name string) (LispValue, bool, error) {
+	if !interp.specialVariables[name] {
+		return nil, false, nil
+	}
+	if val, ok := interp.specialValues[name]; ok {
+		return val, true, nil
+	}
+	return nil, true, &LispError{Message: fmt.Sprintf("unbound variable: %s", name), Line: 0, Column: 0}
+}
+
+// bindSpecial gives a special variable a new dynamic value and returns the
+// function restoring the previous one
+func (interp *Interpreter) bindSpecial(name string, val LispValue) func() {
+	old, bound := interp.specialValues[name]
+	interp.specialValues[name] = val
+	return func() {
+		if bound {
+			interp.specialValues[name] = old
+		} else {
+			delete(interp.specialValues, name)
+		}
+	}
+}
+
+// bindVariable binds a variable of a lambda list or a binding form. A special
+// variable gets a new dynamic value, and the function restoring the previous
+// one is added to restore; any other variable is bound in the local
+// environment.
+func (interp *Interpreter) bindVariable(env Environment, name string, val LispValue, restore *[]func()) {
+	if interp.specialVariables[name] {
+		*restore = append(*restore, interp.bindSpecial(name, val))
+		return
+	}
+	env[name] = val
+}
+
+// unbind restores the special variables bound by bindVariable, the latest
+// binding first
+func unbind(restore *[]func()) {
+	for i := len(*restore) - 1; i >= 0; i-- {
+		(*restore)[i]()
+	}
+}
+
+// builtinDefvar is built-in implementation of defvar and defparameter. Both
+// declare a special variable, whose let bindings are dynamically scoped.
+// defvar only assigns the value if the variable is unbound, while
+// defparameter always assigns it.
+//
+//	(defvar name [value [documentation]])
+//	(defparameter name value [documentation])
+func builtinDefvar(env Environment, args []LispValue, name string) (LispValue, error) {
+	interp := interpreterOf(env)
+	minArgs := 1
+	if name == DEFPARAMETER {
+		minArgs = 2
+	}
+	if len(args) < minArgs || len(args) > 3 {
+		return nil, fmt.Errorf("wrong number of arguments to %s", name)
+	}
+	variable, ok := args[0].(*LispAtom)
+	if !ok {
+		return nil, fmt.Errorf("invalid variable name: %v", args[0])
+	}
+	if len(args) == 3 {
+		if _, ok := args[2].(*LispString); !ok {
+			return nil, fmt.Errorf("invalid documentation string: %v", args[2])
+		}
+	}
+	interp.specialVariables[variable.Value] = true
+	if _, bound := interp.specialValues[variable.Value]; len(args) > 1 && (name == DEFPARAMETER || !bound) {
+		val, err := Eval(env, args[1])
+		if err != nil {
+			return nil, err
+		}
+		interp.specialValues[variable.Value] = val
+	}
+	return variable, nil
+}
+
+// builtinMakeParameter is built-in implementation of make-parameter. The
+// optional converter is applied to the initial value and to the values
+// given by parameterize.
+//
+//	(make-parameter value [converter])
+func builtinMakeParameter(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("wrong number of arguments to make-parameter")
+	}
+	vals, err := evalArgs(env, args)
+	if err != nil {
+		return nil, err
+	}
+	param := &LispParameter{Value: vals[0]}
+	if len(vals) == 2 {
+		if _, ok := vals[1].(*LispFunction); !ok {
+			return nil, fmt.Errorf("invalid parameter converter: %v", vals[1])
+		}
+		param.Converter = vals[1]
+		converted, err := applyFunction(param.Converter, vals[:1])
+		if err != nil {
+			return nil, err
+		}
+		param.Value = primaryValue(converted)
+	}
+	return param, nil
+}
+
+// builtinParameterize is built-in implementation of parameterize. It
+// evaluates the body with parameter objects rebound, and restores their
+// values however the body is left.
+//
+//	(parameterize ((parameter value)...) form...)
+func builtinParameterize(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("wrong number of arguments to parameterize")
+	}
+	bindings, ok := args[0].(*LispList)
+	if !ok {
+		return nil, fmt.Errorf("invalid parameterize bindings: %v", args[0])
+	}
+	params := make([]*LispParameter, 0, len(bindings.Elements))
+	vals := make([]LispValue, 0, len(bindings.Elements))
+	for _, binding := range bindings.Elements {
+		bindList, ok := binding.(*LispList)
+		if !ok || len(bindList.Elements) != 2 {
+			return nil, fmt.Errorf("invalid parameterize binding: %v", binding)
+		}
+		pair, err := evalArgs(env, bindList.Elements)
+		if err != nil {
+			return nil, err
+		}
+		param, ok := pair[0].(*LispParameter)
+		if !ok {
+			return nil, fmt.Errorf("invalid argument to parameterize: %v is not a parameter", pair[0])
+		}
+		val := pair[1]
+		if param.Converter != nil {
+			converted, err := applyFunction(param.Converter, pair[1:])
+			if err != nil {
+				return nil, err
+			}
+			val = primaryValue(converted)
+		}
+		params = append(params, param)
+		vals = append(vals, val)
+	}
+
+	// every value is computed before any parameter is rebound
+	for i, param := range params {
+		defer func(param *LispParameter, old LispValue) { param.Value = old }(param, param.Value)
+		param.Value = vals[i]
+	}
+	return evalBody(env, args[1:])
+}
@@ -0,0 +1,571 @@
+package lisp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Token types
+const (
+	FORMAT                         = "format"
+	PLUS                           = "+"
+	MINUS                          = "-"
+	STAR                           = "*"
+	SLASH                          = "/"
+	PERCENT                        = "%"
+	LESS_THAN                      = "<"
+	LESS_OR_EQUAL_THAN             = "<="
+	GREATER_THAN                   = ">"
+	GREATER_OR_EQUAL_THAN          = ">="
+	EQUAL                          = "="
+	NUM_NOT_EQUAL                  = "/="
+	FLOOR                          = "floor"
+	CEILING                        = "ceiling"
+	ROUND                          = "round"
+	TRUNCATE                       = "truncate"
+	ABS                            = "abs"
+	MIN                            = "min"
+	MAX                            = "max"
+	EXACT                          = "exact?"
+	INEXACT                        = "inexact?"
+	SIN                            = "sin"
+	COS                            = "cos"
+	TAN                            = "tan"
+	ASIN                           = "asin"
+	ACOS                           = "acos"
+	ATAN                           = "atan"
+	ATAN2                          = "atan2"
+	EXP                            = "exp"
+	LOG                            = "log"
+	LOG2                           = "log2"
+	LOG10                          = "log10"
+	GCD                            = "gcd"
+	LCM                            = "lcm"
+	QUOTIENT                       = "quotient"
+	REMAINDER                      = "remainder"
+	MODULO                         = "modulo"
+	EXPT                           = "expt"
+	ISQRT                          = "isqrt"
+	LOGAND                         = "logand"
+	LOGIOR                         = "logior"
+	LOGXOR                         = "logxor"
+	LOGNOT                         = "lognot"
+	ASH                            = "ash"
+	PI                             = "pi"
+	E                              = "e"
+	RANDOM                         = "random"
+	RANDOM_SEED                    = "random-seed"
+	SHUFFLE                        = "shuffle"
+	RANDOM_CHOICE                  = "random-choice"
+	MAKE_RANDOM_STATE              = "make-random-state"
+	ERROR                          = "error"
+	HANDLER_CASE                   = "handler-case"
+	GUARD                          = "guard"
+	IGNORE_ERRORS                  = "ignore-errors"
+	ELSE                           = "else"
+	CONDITION_TYPE_OF              = "condition-type"
+	CONDITION_MESSAGE              = "condition-message"
+	CONDITION_IRRITANTS            = "condition-irritants"
+	CONDITION_POSITION             = "condition-position"
+	IS_ERROR_OBJECT                = "error-object?"
+	ERROR_OBJECT_MESSAGE           = "error-object-message"
+	ERROR_OBJECT_IRRITANTS         = "error-object-irritants"
+	SIGNAL                         = "signal"
+	WARN                           = "warn"
+	HANDLER_BIND                   = "handler-bind"
+	RESTART_CASE                   = "restart-case"
+	INVOKE_RESTART                 = "invoke-restart"
+	COMPUTE_RESTARTS               = "compute-restarts"
+	MUFFLE_WARNING                 = "muffle-warning"
+	DEFINE_CONDITION               = "define-condition"
+	MAKE_CONDITION                 = "make-condition"
+	CONDITION_SLOT                 = "condition-slot"
+	REPORT_KEYWORD                 = ":report"
+	INITARG_KEYWORD                = ":initarg"
+	INITFORM_KEYWORD               = ":initform"
+	READER_KEYWORD                 = ":reader"
+	ACCESSOR_KEYWORD               = ":accessor"
+	UNWIND_PROTECT                 = "unwind-protect"
+	DYNAMIC_WIND                   = "dynamic-wind"
+	CATCH                          = "catch"
+	THROW                          = "throw"
+	BLOCK                          = "block"
+	RETURN_FROM                    = "return-from"
+	RETURN                         = "return"
+	CALL_CC                        = "call/cc"
+	CALL_WITH_CURRENT_CONTINUATION = "call-with-current-continuation"
+	DEFVAR                         = "defvar"
+	DEFPARAMETER                   = "defparameter"
+	MAKE_PARAMETER                 = "make-parameter"
+	PARAMETERIZE                   = "parameterize"
+	VALUES                         = "values"
+	MULTIPLE_VALUE_BIND            = "multiple-value-bind"
+	MULTIPLE_VALUE_LIST            = "multiple-value-list"
+	CALL_WITH_VALUES               = "call-with-values"
+	RECEIVE                        = "receive"
+	FLOOR_DIV                      = "floor/"
+	TRUNCATE_DIV                   = "truncate/"
+	IS_EQUAL                       = "equal"
+	DEFSTRUCT                      = "defstruct"
+	DEFINE_RECORD_TYPE             = "define-record-type"
+	DEFCLASS                       = "defclass"
+	MAKE_INSTANCE                  = "make-instance"
+	SLOT_VALUE                     = "slot-value"
+	SET_SLOT_VALUE                 = "set-slot-value"
+	CLASS_OF                       = "class-of"
+	FIND_CLASS                     = "find-class"
+	DEFGENERIC                     = "defgeneric"
+	DEFMETHOD                      = "defmethod"
+	CALL_NEXT_METHOD               = "call-next-method"
+	NEXT_METHOD_P                  = "next-method-p"
+	WRITER_KEYWORD                 = ":writer"
+	DOCUMENTATION_KEYWORD          = ":documentation"
+	TYPE_KEYWORD                   = ":type"
+	MATCH                          = "match"
+	DESTRUCTURING_BIND             = "destructuring-bind"
+	WILDCARD_PATTERN               = "_"
+	PREDICATE_PATTERN              = "?"
+	STRUCT_PATTERN                 = "struct"
+	OPTIONAL_MARKER                = "&optional"
+	REST_MARKER                    = "&rest"
+	BODY_MARKER                    = "&body"
+	KEY_MARKER                     = "&key"
+	HELP                           = "help"
+	IF                             = "if"
+	DEFUN                          = "defun"
+	LAMBDA                         = "lambda"
+	LET                            = "let"
+	AND                            = "and"
+	OR                             = "or"
+	NOT                            = "not"
+	LIST                           = "list"
+	CAR                            = "car"
+	CDR                            = "cdr"
+	CONS                           = "cons"
+	LENGTH                         = "length"
+	APPEND                         = "append"
+	POW                            = "pow"
+	SQRT                           = "sqrt"
+	CONCAT                         = "concat"
+	SUBSTRING                      = "substring"
+	IS_NUMBER                      = "isNumber"
+	IS_STRING                      = "isString"
+	READ                           = "read"
+	READ_LINE                      = "read-line"
+	READ_CHAR                      = "read-char"
+	PEEK_CHAR                      = "peek-char"
+	READ_FROM_STRING               = "read-from-string"
+	PRINT                          = "print"
+	QUOTE                          = "quote"
+	OPEN_BRACKET                   = '('
+	CLOSE_BRACKET                  = ')'
+	DOUBLE_QUOTE                   = '"'
+	SINGLE_QUOTE                   = '\''
+	SEMICOLON                      = ';'
+	EMPTY_STRING                   = " "
+	DOUBLE_ANTI_SLASH              = '\\'
+	ANTI_SLASH_N                   = '\n'
+	DOT                            = "."
+	KEYWORD_PREFIX                 = ":"
+	TRUE                           = "true"
+	FALSE                          = "false"
+	NIL                            = "nil"
+	T                              = "t"
+	NUMBER                         = "NUMBER"
+	FLOAT                          = "FLOAT"
+	RATIONAL                       = "RATIONAL"
+	ILLEGAL                        = "ILLEGAL"
+	STRING                         = "STRING"
+	EOF                            = "EOF"
+	IDENTIFIER                     = "IDENTIFIER"
+	BOOLEAN                        = "BOOLEAN"
+	FUNCTION                       = "FUNCTION"
+)
+
+// Token represents a token
+type Token struct {
+	Type   string
+	Value  string
+	Line   int
+	Column int
+}
+
+// Tokenize splits the input string into tokens, leaving out what it can't
+// read. Lex also returns the errors.
+func Tokenize(input string) []Token {
+	tokens, _ := Lex(input)
+	return tokens
+}
+
+// Lex splits the input string into tokens. The error lists the lexical errors
+// as SyntaxErrors, unless there are none.
+func Lex(input string) ([]Token, error) {
+	tokens := make([]Token, 0, len(input)/2)
+	lexer := NewLexer(strings.NewReader(input))
+	for {
+		token, err := lexer.Next()
+		if err != nil {
+			break
+		}
+		tokens = append(tokens, token)
+	}
+	if errs := lexer.Errors(); len(errs) > 0 {
+		return tokens, errs
+	}
+	return tokens, nil
+}
+
+// Lexer splits the runes it pulls from a reader into tokens, one at a time.
+// Lines and columns count from 1, and columns count runes, so a tab or a
+// multibyte character takes a single column. Comments run from a semicolon
+// to the end of the line and are skipped. The lexer skips over what it can't
+// read and reports it through Errors.
+type Lexer struct {
+	reader io.RuneReader
+	// scanner is the reader when it can unread runes, so the lexer leaves the
+	// bracket or quote ending a token in it
+	scanner io.RuneScanner
+	// pending holds the tokens read but not returned yet
+	pending    []Token
+	token      strings.Builder
+	inString   bool
+	escapeNext bool
+	// inComment is set from a semicolon to the end of its line
+	inComment bool
+	line       int
+	column     int
+	// tokenLine and tokenColumn locate the first rune of the token read, or
+	// the opening quote of the string read
+	tokenLine   int
+	tokenColumn int
+	// errors holds the lexical errors found so far
+	errors SyntaxErrors
+}
+
+// NewLexer returns a lexer of the runes of a reader. The reader is buffered
+// unless it reads runes already.
+func NewLexer(r io.Reader) *Lexer {
+	reader, ok := r.(io.RuneReader)
+	if !ok {
+		reader = bufio.NewReader(r)
+	}
+	scanner, _ := reader.(io.RuneScanner)
+	return &Lexer{reader: reader, scanner: scanner, line: 1, column: 1}
+}
+
+// Next returns the next token, or io.EOF once the reader is exhausted. It
+// reads no further than the end of the token, and when the reader can unread
+// runes, it leaves the bracket or quote ending a symbol or a number unread.
+func (l *Lexer) Next() (Token, error) {
+	for len(l.pending) == 0 {
+		char, _, err := l.reader.ReadRune()
+		if err == io.EOF {
+			if l.inString {
+				l.fail("unterminated string", l.tokenLine, l.tokenColumn, l.line, l.column)
+				l.inString = false
+				l.token.Reset()
+			}
+			if l.token.Len() == 0 {
+				return Token{}, io.EOF
+			}
+			l.flush()
+			break
+		}
+		if err != nil {
+			return Token{}, err
+		}
+		if l.scanner != nil && l.token.Len() > 0 && !l.inString && isDelimiter(char) {
+			l.scanner.UnreadRune()
+			l.flush()
+			break
+		}
+		l.scan(char)
+	}
+	token := l.pending[0]
+	l.pending = l.pending[1:]
+	return token, nil
+}
+
+// Errors returns the lexical errors found so far, in the order of the input
+func (l *Lexer) Errors() SyntaxErrors {
+	return append(SyntaxErrors(nil), l.errors...)
+}
+
+// fail records a lexical error
+func (l *Lexer) fail(message string, line, column, endLine, endColumn int) {
+	l.errors = append(l.errors, &SyntaxError{Message: message, Line: line, Column: column, EndLine: endLine, EndColumn: endColumn})
+}
+
+// write adds a rune to the token read, noting where a token starts
+func (l *Lexer) write(char rune) {
+	if l.token.Len() == 0 && !l.inString {
+		l.tokenLine, l.tokenColumn = l.line, l.column
+	}
+	l.token.WriteRune(char)
+}
+
+// flush turns the runes read since the end of the previous token into a token
+func (l *Lexer) flush() {
+	if l.token.Len() > 0 {
+		l.pending = append(l.pending, createToken(l.token.String(), l.tokenLine, l.tokenColumn))
+		l.token.Reset()
+	}
+}
+
+// escapes maps the characters following a backslash in a string to the
+// characters they stand for
+var escapes = map[rune]rune{
+	DOUBLE_QUOTE:      DOUBLE_QUOTE,
+	DOUBLE_ANTI_SLASH: DOUBLE_ANTI_SLASH,
+	'n':               '\n',
+	't':               '\t',
+	'r':               '\r',
+}
+
+// scan reads a rune
+func (l *Lexer) scan(char rune) {
+	defer l.advance(char)
+	switch {
+	case l.inComment:
+		l.inComment = char != ANTI_SLASH_N
+	case unicode.IsControl(char) && !unicode.IsSpace(char):
+		l.fail(fmt.Sprintf("invalid control character %U", char), l.line, l.column, l.line, l.column+1)
+	case l.escapeNext:
+		l.escapeNext = false
+		escaped, ok := escapes[char]
+		if !ok {
+			l.fail(fmt.Sprintf("invalid escape \\%c in string", char), l.line, l.column-1, l.line, l.column+1)
+			escaped = char
+		}
+		l.token.WriteRune(escaped)
+	case l.inString:
+		switch char {
+		case DOUBLE_QUOTE:
+			l.inString = false
+			l.pending = append(l.pending, Token{Type: STRING, Value: l.token.String(), Line: l.tokenLine, Column: l.tokenColumn + 1})
+			l.token.Reset()
+		case DOUBLE_ANTI_SLASH:
+			l.escapeNext = true
+		default:
+			l.token.WriteRune(char)
+		}
+	case unicode.IsSpace(char):
+		l.flush()
+	case char == OPEN_BRACKET || char == CLOSE_BRACKET || char == SINGLE_QUOTE:
+		l.flush()
+		l.pending = append(l.pending, Token{Type: string(char), Value: string(char), Line: l.line, Column: l.column})
+	case char == SEMICOLON:
+		l.flush()
+		l.inComment = true
+	case char == DOUBLE_QUOTE:
+		l.flush()
+		l.inString = true
+		l.tokenLine, l.tokenColumn = l.line, l.column
+	default:
+		l.write(char)
+	}
+}
+
+// isDelimiter checks that a rune ends the token before it and starts another
+// token or a comment
+func isDelimiter(char rune) bool {
+	return char == OPEN_BRACKET || char == CLOSE_BRACKET || char == SINGLE_QUOTE || char == DOUBLE_QUOTE || char == SEMICOLON
+}
+
+// advance moves the position past a rune
+func (l *Lexer) advance(char rune) {
+	if char == ANTI_SLASH_N {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+}
+
+// createToken creates a token based on the value
+func createToken(value string, line, column int) Token {
+	tokenType := IDENTIFIER
+	switch value {
+	case TRUE, FALSE:
+		tokenType = BOOLEAN
+	case NIL:
+		tokenType = NIL
+	default:
+		if looksNumeric(value) {
+			switch num, err := parseNumber(value); {
+			case err != nil:
+				tokenType = ILLEGAL
+			case isExact(num):
+				if _, ok := num.(*LispNumber); ok {
+					tokenType = NUMBER
+				} else {
+					tokenType = RATIONAL
+				}
+			default:
+				tokenType = FLOAT
+			}
+		}
+	}
+	return Token{Type: tokenType, Value: value, Line: line, Column: column}
+}
+
+// decimalLiteral matches a decimal number once digit separators are removed
+var decimalLiteral = regexp.MustCompile(`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+
+// looksNumeric reports whether a token has to be read as a number. Such a
+// token is either a valid number literal or a malformed one, never a symbol.
+func looksNumeric(value string) bool {
+	s := value
+	if len(s) >= 2 && s[0] == '#' {
+		return strings.ContainsRune("xXoObBdDeEiI", rune(s[1]))
+	}
+	switch strings.ToLower(s) {
+	case "+inf.0", "-inf.0", "+nan.0", "-nan.0":
+		return true
+	}
+	if len(s) > 1 && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	if len(s) > 1 && s[0] == '.' {
+		s = s[1:]
+	}
+	return len(s) > 0 && s[0] >= '0' && s[0] <= '9'
+}
+
+// parseNumber parses a number literal. It accepts the radix prefixes #x, #o,
+// #b and #d, the exactness prefixes #e and #i, ratios such as 1/3, decimals
+// with exponents, _ digit separators and the special floats +inf.0, -inf.0,
+// +nan.0 and -nan.0.
+func parseNumber(text string) (LispValue, error) {
+	radix, exactness := 0, byte(0)
+	s := text
+	for len(s) >= 2 && s[0] == '#' {
+		switch prefix := unicode.ToLower(rune(s[1])); prefix {
+		case 'x', 'o', 'b', 'd':
+			if radix != 0 {
+				return nil, fmt.Errorf("malformed number: %s: more than one radix prefix", text)
+			}
+			radix = map[rune]int{'x': 16, 'o': 8, 'b': 2, 'd': 10}[prefix]
+		case 'e', 'i':
+			if exactness != 0 {
+				return nil, fmt.Errorf("malformed number: %s: more than one exactness prefix", text)
+			}
+			exactness = byte(prefix)
+		default:
+			return nil, fmt.Errorf("malformed number: %s: unknown prefix #%c", text, s[1])
+		}
+		s = s[2:]
+	}
+	if radix == 0 {
+		radix = 10
+	}
+
+	var num LispValue
+	switch strings.ToLower(s) {
+	case "+inf.0":
+		num = &LispFloat{Value: math.Inf(1)}
+	case "-inf.0":
+		num = &LispFloat{Value: math.Inf(-1)}
+	case "+nan.0", "-nan.0":
+		num = &LispFloat{Value: math.NaN()}
+	default:
+		digits, ok := removeDigitSeparators(s, radix)
+		if !ok {
+			return nil, fmt.Errorf("malformed number: %s: misplaced digit separator", text)
+		}
+		if radix == 10 && strings.ContainsAny(digits, ".eE") {
+			if !decimalLiteral.MatchString(digits) {
+				return nil, fmt.Errorf("malformed number: %s", text)
+			}
+			if exactness == 'e' {
+				r, ok := new(big.Rat).SetString(digits)
+				if !ok {
+					return nil, fmt.Errorf("malformed number: %s", text)
+				}
+				return makeRational(r), nil
+			}
+			f, err := strconv.ParseFloat(digits, 64)
+			if err != nil {
+				return nil, fmt.Errorf("malformed number: %s: out of range", text)
+			}
+			num = &LispFloat{Value: f}
+		} else {
+			r, err := parseExactNumber(digits, radix)
+			if err != nil {
+				return nil, fmt.Errorf("malformed number: %s: %v", text, err)
+			}
+			num = makeRational(r)
+		}
+	}
+
+	switch {
+	case exactness == 'i' && isExact(num):
+		return &LispFloat{Value: toFloat(num)}, nil
+	case exactness == 'e' && !isExact(num):
+		return nil, fmt.Errorf("malformed number: %s: no exact representation", text)
+	}
+	return num, nil
+}
+
+// parseExactNumber parses an integer or a ratio in the given radix
+func parseExactNumber(s string, radix int) (*big.Rat, error) {
+	negative := false
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+	numText, denText, isRatio := strings.Cut(s, "/")
+	num, ok := parseDigits(numText, radix)
+	if !ok {
+		return nil, fmt.Errorf("invalid digits for radix %d", radix)
+	}
+	den := big.NewInt(1)
+	if isRatio {
+		if den, ok = parseDigits(denText, radix); !ok {
+			return nil, fmt.Errorf("invalid digits for radix %d", radix)
+		}
+		if den.Sign() == 0 {
+			return nil, fmt.Errorf("zero denominator")
+		}
+	}
+	if negative {
+		num.Neg(num)
+	}
+	return new(big.Rat).SetFrac(num, den), nil
+}
+
+// parseDigits parses an unsigned sequence of digits in the given radix
+func parseDigits(s string, radix int) (*big.Int, bool) {
+	if s == "" || s[0] == '+' || s[0] == '-' {
+		return nil, false
+	}
+	return new(big.Int).SetString(s, radix)
+}
+
+// removeDigitSeparators strips the _ separators of a number literal. Each
+// separator has to sit between two digits, as in 1_000_000.
+func removeDigitSeparators(s string, radix int) (string, bool) {
+	if !strings.Contains(s, "_") {
+		return s, true
+	}
+	isDigit := func(i int) bool {
+		if i < 0 || i >= len(s) {
+			return false
+		}
+		digit, err := strconv.ParseUint(s[i:i+1], radix, 8)
+		return err == nil && int(digit) < radix
+	}
+	for i := range s {
+		if s[i] == '_' && (!isDigit(i-1) || !isDigit(i+1)) {
+			return "", false
+		}
+	}
+	return strings.ReplaceAll(s, "_", ""), true
+}
@@ -0,0 +1,279 @@
+// Package lisp implements the lexer, parser and evaluator of the Lisp
+// interpreter, so Go programs can embed it.
+//
+//	interp := lisp.NewInterpreter()
+//	result, err := interp.EvalString(ctx, "(+ 1 2)")
+package lisp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// INTERPRETER_KEY binds the interpreter in the environments it evaluates in,
+// so builtins reach the state of the interpreter running them. The brackets
+// keep the reader from ever producing a symbol of that name, so programs
+// can't rebind it.
+const INTERPRETER_KEY = "(interpreter)"
+
+// Interpreter holds everything a Lisp program can change: the global
+// environment, the dynamic state of handlers, restarts and catch tags,
+// special variables, classes, condition types and the random generator.
+// Interpreters don't share any state, but one interpreter must not be used
+// by several goroutines at once.
+type Interpreter struct {
+	// Debugger is called with an error no handler took care of, before the
+	// stack unwinds, so the active restarts can still be invoked. It returns
+	// the control transfer to perform, or nil to let the error unwind.
+	Debugger func(condition *LispCondition) error
+
+	env Environment
+	// ctx is the context of the running evaluation, and done its Done channel
+	ctx  context.Context
+	done <-chan struct{}
+	// builtins maps the names of the builtins to their implementation
+	builtins map[string]*Builtin
+	// inputPort buffers the current input port, created on first use
+	inputPort *bufio.Reader
+	// inputReader parses the forms of the input port for read, keeping its
+	// lookahead and position between calls
+	inputReader *Reader
+	// outputPort receives the prompts of read and the debugger, stdout when nil
+	outputPort io.Writer
+	// errorPort receives the warnings no handler muffled, stderr when nil
+	errorPort io.Writer
+	// sandbox restricts the evaluations, unless it is nil
+	sandbox *sandbox
+	// parseCache holds the expressions parsed from the source texts read lately
+	parseCache *parseCache
+
+	// handlerClusters holds the active handlers, innermost cluster last
+	handlerClusters [][]handlerBinding
+	// restartStack holds the active restarts, innermost last
+	restartStack []restartPoint
+	// catchStack holds the active catch tags, innermost last
+	catchStack []catchPoint
+	// frameCounter numbers the forms that can be unwound to
+	frameCounter int
+
+	// specialVariables holds the names declared special by defvar and defparameter
+	specialVariables map[string]bool
+	// specialValues holds the current dynamic value of each bound special variable
+	specialValues map[string]LispValue
+
+	// conditionTypes maps each condition type name to its definition
+	conditionTypes map[string]*conditionType
+	// classes maps class names to classes
+	classes map[string]*LispClass
+	// recordClasses holds the classes of the record types, created on demand
+	recordClasses map[*LispRecordType]*LispClass
+
+	// randomState is the generator used when no random state is passed explicitly
+	randomState *LispRandomState
+}
+
+// CancelledError is returned by an evaluation whose context was cancelled or
+// timed out. Condition handlers can't catch it.
+type CancelledError struct {
+	Cause error
+}
+
+// Error returns the error message
+func (e *CancelledError) Error() string {
+	return fmt.Sprintf("evaluation cancelled: %v", e.Cause)
+}
+
+// Unwrap returns the error of the context
+func (e *CancelledError) Unwrap() error {
+	return e.Cause
+}
+
+func (e *CancelledError) controlTransfer() {}
+
+// checkContext returns a CancelledError once the context of the running
+// evaluation is done. It is called on every function call.
+func (interp *Interpreter) checkContext() error {
+	select {
+	case <-interp.done:
+		return &CancelledError{Cause: interp.ctx.Err()}
+	default:
+		return nil
+	}
+}
+
+// detached calls fn with the cancellation of the running evaluation turned
+// off, so the cleanup forms of an evaluation run even once it was cancelled
+func (interp *Interpreter) detached(fn func() (LispValue, error)) (LispValue, error) {
+	saved, savedDone := interp.ctx, interp.done
+	if saved != nil {
+		interp.ctx = context.WithoutCancel(saved)
+	}
+	interp.done = nil
+	defer func() { interp.ctx, interp.done = saved, savedDone }()
+	return fn()
+}
+
+// NewInterpreter returns an interpreter with the predefined symbols bound
+func NewInterpreter() *Interpreter {
+	interp := &Interpreter{
+		builtins:         newBuiltins(),
+		parseCache:       newParseCache(PARSE_CACHE_SIZE),
+		specialVariables: map[string]bool{},
+		specialValues:    map[string]LispValue{},
+		conditionTypes:   newConditionTypes(),
+		classes:          newClasses(),
+		recordClasses:    map[*LispRecordType]*LispClass{},
+		randomState:      &LispRandomState{State: uint64(time.Now().UnixNano())},
+	}
+	interp.env = Environment{
+		INTERPRETER_KEY: interp,
+		T:               &LispBoolean{Value: true},
+		NIL:             &LispNil{},
+		TRUE:            &LispBoolean{Value: true},
+		FALSE:           &LispBoolean{Value: false},
+		PI:              &LispFloat{Value: math.Pi},
+		E:               &LispFloat{Value: math.E},
+	}
+	return interp
+}
+
+// String returns the string representation of the interpreter
+func (interp *Interpreter) String() string {
+	return "#<interpreter>"
+}
+
+// interpreterOf returns the interpreter an environment belongs to. Every
+// environment evaluated in derives from the global environment of an
+// interpreter, so it panics on any other.
+func interpreterOf(env Environment) *Interpreter {
+	interp, ok := env[INTERPRETER_KEY].(*Interpreter)
+	if !ok {
+		panic("lisp: environment not made by an interpreter")
+	}
+	return interp
+}
+
+// Define binds a name in the global environment
+func (interp *Interpreter) Define(name string, value LispValue) {
+	interp.env[name] = value
+}
+
+// Lookup returns the value of a global or special variable
+func (interp *Interpreter) Lookup(name string) (LispValue, bool) {
+	if val, special, err := interp.lookupSpecial(name); special {
+		return val, err == nil
+	}
+	val, ok := interp.env[name]
+	return val, ok
+}
+
+// Symbols returns the sorted names bound in the global environment and the
+// names of the special variables
+func (interp *Interpreter) Symbols() (globals []string, specials []string) {
+	for name := range interp.env {
+		if name != INTERPRETER_KEY && !strings.HasPrefix(name, "%") {
+			globals = append(globals, name)
+		}
+	}
+	for name := range interp.specialValues {
+		specials = append(specials, name)
+	}
+	sort.Strings(globals)
+	sort.Strings(specials)
+	return globals, specials
+}
+
+// Seed resets the default random generator from a seed
+func (interp *Interpreter) Seed(seed int64) {
+	interp.randomState.Seed(seed)
+}
+
+// Read parses the top-level forms of source text
+func (interp *Interpreter) Read(src string) ([]LispValue, error) {
+	return interp.parse(src)
+}
+
+// EvalForm evaluates a top-level form in the global environment, keeping
+// every value of a form returning multiple values. The evaluation stops with
+// a CancelledError when ctx is done.
+func (interp *Interpreter) EvalForm(ctx context.Context, form LispValue) (LispValue, error) {
+	saved, savedDone := interp.ctx, interp.done
+	interp.ctx, interp.done = ctx, ctx.Done()
+	defer func() { interp.ctx, interp.done = saved, savedDone }()
+	defer interp.startRun()()
+	if err := interp.checkContext(); err != nil {
+		return nil, err
+	}
+	return evalValues(interp.env, form)
+}
+
+// EvalForms evaluates the forms of source text in turn and returns their
+// results
+func (interp *Interpreter) EvalForms(ctx context.Context, src string) ([]LispValue, error) {
+	forms, err := interp.Read(src)
+	if err != nil {
+		return nil, err
+	}
+	defer interp.startRun()()
+	results := make([]LispValue, 0, len(forms))
+	for _, form := range forms {
+		result, err := interp.EvalForm(ctx, form)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// EvalString evaluates the forms of source text in the global environment
+// and returns the value of the last one
+func (interp *Interpreter) EvalString(ctx context.Context, src string) (LispValue, error) {
+	results, err := interp.EvalForms(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return &LispNil{}, nil
+	}
+	return results[len(results)-1], nil
+}
+
+// EvalReader evaluates the forms read from r as they arrive and returns the
+// value of the last one
+func (interp *Interpreter) EvalReader(ctx context.Context, r io.Reader) (LispValue, error) {
+	reader := NewReader(r)
+	defer interp.startRun()()
+	var result LispValue = &LispNil{}
+	for {
+		form, err := reader.Next()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		result, err = interp.EvalForm(ctx, form)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// EvalFile evaluates the forms of a file as they are read and returns the
+// value of the last one
+func (interp *Interpreter) EvalFile(ctx context.Context, path string) (LispValue, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return interp.EvalReader(ctx, file)
+}
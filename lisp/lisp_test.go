@@ -0,0 +1,2638 @@
+package lisp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTokenize tests the Tokenize function
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []Token
+	}{
+		{
+			"(define x 10)",
+			[]Token{
+				{Type: string(OPEN_BRACKET), Value: string(OPEN_BRACKET), Line: 1, Column: 1},
+				{Type: IDENTIFIER, Value: "define", Line: 1, Column: 2},
+				{Type: IDENTIFIER, Value: "x", Line: 1, Column: 9},
+				{Type: NUMBER, Value: "10", Line: 1, Column: 11},
+				{Type: string(CLOSE_BRACKET), Value: string(CLOSE_BRACKET), Line: 1, Column: 13},
+			},
+		},
+		{
+			"(+ 1 2)",
+			[]Token{
+				{Type: string(OPEN_BRACKET), Value: string(OPEN_BRACKET), Line: 1, Column: 1},
+				{Type: IDENTIFIER, Value: PLUS, Line: 1, Column: 2},
+				{Type: NUMBER, Value: "1", Line: 1, Column: 4},
+				{Type: NUMBER, Value: "2", Line: 1, Column: 6},
+				{Type: string(CLOSE_BRACKET), Value: string(CLOSE_BRACKET), Line: 1, Column: 7},
+			},
+		},
+		{
+			"(print \"hello world\")",
+			[]Token{
+				{Type: string(OPEN_BRACKET), Value: string(OPEN_BRACKET), Line: 1, Column: 1},
+				{Type: IDENTIFIER, Value: "print", Line: 1, Column: 2},
+				{Type: STRING, Value: "hello world", Line: 1, Column: 9},
+				{Type: string(CLOSE_BRACKET), Value: string(CLOSE_BRACKET), Line: 1, Column: 21},
+			},
+		},
+		{
+			"(if (> x 10) \"yes\" \"no\")",
+			[]Token{
+				{Type: string(OPEN_BRACKET), Value: string(OPEN_BRACKET), Line: 1, Column: 1},
+				{Type: IDENTIFIER, Value: IF, Line: 1, Column: 2},
+				{Type: string(OPEN_BRACKET), Value: string(OPEN_BRACKET), Line: 1, Column: 5},
+				{Type: IDENTIFIER, Value: GREATER_THAN, Line: 1, Column: 6},
+				{Type: IDENTIFIER, Value: "x", Line: 1, Column: 8},
+				{Type: NUMBER, Value: "10", Line: 1, Column: 10},
+				{Type: string(CLOSE_BRACKET), Value: string(CLOSE_BRACKET), Line: 1, Column: 12},
+				{Type: STRING, Value: "yes", Line: 1, Column: 15},
+				{Type: STRING, Value: "no", Line: 1, Column: 21},
+				{Type: string(CLOSE_BRACKET), Value: string(CLOSE_BRACKET), Line: 1, Column: 24},
+			},
+		},
+		{
+			"(+ #xFF 1e3 1/2 1abc)",
+			[]Token{
+				{Type: string(OPEN_BRACKET), Value: string(OPEN_BRACKET), Line: 1, Column: 1},
+				{Type: IDENTIFIER, Value: PLUS, Line: 1, Column: 2},
+				{Type: NUMBER, Value: "#xFF", Line: 1, Column: 4},
+				{Type: FLOAT, Value: "1e3", Line: 1, Column: 9},
+				{Type: RATIONAL, Value: "1/2", Line: 1, Column: 13},
+				{Type: ILLEGAL, Value: "1abc", Line: 1, Column: 17},
+				{Type: string(CLOSE_BRACKET), Value: string(CLOSE_BRACKET), Line: 1, Column: 21},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		result := Tokenize(test.input)
+		if !lispValueEqual(result, test.expected) {
+			t.Errorf("Tokenize(%q) = %v, want %v", test.input, result, test.expected)
+		}
+	}
+}
+
+// TestLex tests the positions and the errors of the lexer
+func TestLex(t *testing.T) {
+	tests := []struct {
+		input  string
+		tokens []Token
+		errors []string
+	}{
+		{
+			"(λ\tx \"é\")",
+			[]Token{
+				{Type: string(OPEN_BRACKET), Value: string(OPEN_BRACKET), Line: 1, Column: 1},
+				{Type: IDENTIFIER, Value: "λ", Line: 1, Column: 2},
+				{Type: IDENTIFIER, Value: "x", Line: 1, Column: 4},
+				{Type: STRING, Value: "é", Line: 1, Column: 7},
+				{Type: string(CLOSE_BRACKET), Value: string(CLOSE_BRACKET), Line: 1, Column: 9},
+			},
+			nil,
+		},
+		{
+			`"say \"hi\"\n\\"`,
+			[]Token{{Type: STRING, Value: "say \"hi\"\n\\", Line: 1, Column: 2}},
+			nil,
+		},
+		{
+			"\"two\nlines\" x",
+			[]Token{
+				{Type: STRING, Value: "two\nlines", Line: 1, Column: 2},
+				{Type: IDENTIFIER, Value: "x", Line: 2, Column: 8},
+			},
+			nil,
+		},
+		{
+			"; a comment (with \"brackets\"\n(f x;inline\n \"a;b\") ;\x01 last",
+			[]Token{
+				{Type: string(OPEN_BRACKET), Value: string(OPEN_BRACKET), Line: 2, Column: 1},
+				{Type: IDENTIFIER, Value: "f", Line: 2, Column: 2},
+				{Type: IDENTIFIER, Value: "x", Line: 2, Column: 4},
+				{Type: STRING, Value: "a;b", Line: 3, Column: 3},
+				{Type: string(CLOSE_BRACKET), Value: string(CLOSE_BRACKET), Line: 3, Column: 7},
+			},
+			nil,
+		},
+		{
+			`(f "ab\q")`,
+			[]Token{
+				{Type: string(OPEN_BRACKET), Value: string(OPEN_BRACKET), Line: 1, Column: 1},
+				{Type: IDENTIFIER, Value: "f", Line: 1, Column: 2},
+				{Type: STRING, Value: "abq", Line: 1, Column: 5},
+				{Type: string(CLOSE_BRACKET), Value: string(CLOSE_BRACKET), Line: 1, Column: 10},
+			},
+			[]string{"1:7-1:9 invalid escape \\q in string"},
+		},
+		{
+			"a\x01b\n\"ü\x07",
+			[]Token{{Type: IDENTIFIER, Value: "ab", Line: 1, Column: 1}},
+			[]string{"1:2-1:3 invalid control character U+0001", "2:3-2:4 invalid control character U+0007", "2:1-2:4 unterminated string"},
+		},
+	}
+
+	for _, test := range tests {
+		tokens, err := Lex(test.input)
+		if !lispValueEqual(tokens, test.tokens) {
+			t.Errorf("Lex(%q) tokens = %v, want %v", test.input, tokens, test.tokens)
+		}
+		var got []string
+		var errs SyntaxErrors
+		if errors.As(err, &errs) {
+			for _, e := range errs {
+				got = append(got, fmt.Sprintf("%d:%d-%d:%d %s", e.Line, e.Column, e.EndLine, e.EndColumn, e.Message))
+			}
+		} else if err != nil {
+			t.Errorf("Lex(%q) error = %v, want SyntaxErrors", test.input, err)
+		}
+		if !reflect.DeepEqual(got, test.errors) {
+			t.Errorf("Lex(%q) errors = %q, want %q", test.input, got, test.errors)
+		}
+	}
+}
+
+// TestParseNumber tests the parseNumber function
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected LispValue
+		err      bool
+	}{
+		{"#xFF", &LispNumber{Value: 255}, false},
+		{"#b1010", &LispNumber{Value: 10}, false},
+		{"#o-17", &LispNumber{Value: -15}, false},
+		{"1_000_000", &LispNumber{Value: 1000000}, false},
+		{"1e3", &LispFloat{Value: 1000}, false},
+		{"-2.5e-1", &LispFloat{Value: -0.25}, false},
+		{"6/4", &LispRational{Value: big.NewRat(3, 2)}, false},
+		{"#e1.5", &LispRational{Value: big.NewRat(3, 2)}, false},
+		{"#i1/4", &LispFloat{Value: 0.25}, false},
+		{"#x#e10", &LispNumber{Value: 16}, false},
+		{"-inf.0", &LispFloat{Value: math.Inf(-1)}, false},
+		{"1abc", nil, true},
+		{"1__0", nil, true},
+		{"#b102", nil, true},
+		{"1/0", nil, true},
+		{"#x1.5", nil, true},
+		{"#e+nan.0", nil, true},
+	}
+
+	for _, test := range tests {
+		result, err := parseNumber(test.input)
+		if (err != nil) != test.err || (err == nil && !lispValueEqual(result, test.expected)) {
+			t.Errorf("parseNumber(%q) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+}
+
+// TestParse tests the Parse function
+func TestParse(t *testing.T) {
+	tests := []struct {
+		tokens   []Token
+		expected LispValue
+	}{
+		{
+			[]Token{{Type: NUMBER, Value: "10", Line: 1, Column: 1}},
+			&LispNumber{Value: 10},
+		},
+		{
+			[]Token{{Type: STRING, Value: "hello", Line: 1, Column: 1}},
+			&LispString{Value: "hello"},
+		},
+		{
+			[]Token{
+				{Type: string(OPEN_BRACKET), Value: string(OPEN_BRACKET), Line: 1, Column: 1},
+				{Type: IDENTIFIER, Value: PLUS, Line: 1, Column: 2},
+				{Type: NUMBER, Value: "1", Line: 1, Column: 4},
+				{Type: NUMBER, Value: "2", Line: 1, Column: 6},
+				{Type: string(CLOSE_BRACKET), Value: string(CLOSE_BRACKET), Line: 1, Column: 7},
+			},
+			&LispList{
+				Elements: []LispValue{
+					&LispAtom{Value: PLUS, Line: 1, Column: 2},
+					&LispNumber{Value: 1},
+					&LispNumber{Value: 2},
+				},
+				Line:   1,
+				Column: 1,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		result, _, err := Parse(test.tokens)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("Parse(%v) = %v, %v, want %v", test.tokens, result, err, test.expected)
+		}
+	}
+}
+
+// TestEval tests the Eval function
+func TestEval(t *testing.T) {
+	env := newTestEnvironment()
+	env["x"] = &LispNumber{Value: 10}
+
+	tests := []struct {
+		expr     LispValue
+		expected LispValue
+	}{
+		{&LispNumber{Value: 10}, &LispNumber{Value: 10}},
+		{&LispString{Value: "hello"}, &LispString{Value: "hello"}},
+		{&LispAtom{Value: "x"}, &LispNumber{Value: 10}},
+		{&LispList{Elements: []LispValue{&LispAtom{Value: "+"}, &LispNumber{Value: 1}, &LispNumber{Value: 2}}}, &LispNumber{Value: 3}},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, test.expr)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("Eval(%v) = %v, %v, want %v", test.expr, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinFormat tests the builtinFormat function
+func TestBuiltinFormat(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispString{Value: "t"}, &LispString{Value: "Hello"}}, &LispString{Value: "Hello"}},
+		{[]LispValue{&LispString{Value: "t"}, &LispString{Value: "Factorial of 5 is %d"}, &LispNumber{Value: 120}}, &LispString{Value: "Factorial of 5 is 120"}},
+		{[]LispValue{&LispString{Value: "t"}, &LispString{Value: "%s %v %v"}, parseExpr(t, "'sym"), parseExpr(t, "'(1 2)"), parseExpr(t, "1/3")}, &LispString{Value: "sym (1 2) 1/3"}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinFormat(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinFormat(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinRead tests read, read-line, read-char, peek-char and
+// read-from-string, which share the buffer of the input port
+func TestBuiltinRead(t *testing.T) {
+	interp := NewInterpreter()
+	interp.SetInput(strings.NewReader("(+ 1\n 2) hello(x)\nrest of the line\n\"é\" 42"))
+	var output strings.Builder
+	interp.SetOutput(&output)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"(read)", "(+ 1 2)"},
+		{`(read "name? ")`, "hello"},
+		{"(peek-char)", `"("`},
+		{"(read 'next)", "(x)"},
+		{"(read-line)", `""`},
+		{"(read-line)", `"rest of the line"`},
+		{"(read-char)", `"""`},
+		{"(read-char)", `"é"`},
+		{"(read-char)", `"""`},
+		{"(read)", "42"},
+		{"(read-line)", "nil"},
+		{"(read-char)", "nil"},
+		{`(read-from-string "(a 'b) c")`, "(a (quote b))"},
+	}
+
+	for _, test := range tests {
+		result, err := interp.EvalString(context.Background(), test.input)
+		if err != nil || result.String() != test.expected {
+			t.Errorf("%s = %v, %v, want %s", test.input, result, err, test.expected)
+		}
+	}
+
+	if output.String() != "name? next" {
+		t.Errorf("read printed %q to the output port, want %q", output.String(), "name? next")
+	}
+
+	interp.SetInput(strings.NewReader("(a)\n(b))\n(c)"))
+	for _, input := range []string{"(read)", "(read)"} {
+		if _, err := interp.EvalString(context.Background(), input); err != nil {
+			t.Fatalf("%s = %v", input, err)
+		}
+	}
+	_, err := interp.EvalString(context.Background(), "(read)")
+	var syntaxErrors SyntaxErrors
+	if !errors.As(err, &syntaxErrors) || syntaxErrors[0].Line != 2 || syntaxErrors[0].Column != 4 {
+		t.Errorf("(read) = %v, want a syntax error at line 2, column 4", err)
+	}
+	if result, err := interp.EvalString(context.Background(), "(read)"); err != nil || result.String() != "(c)" {
+		t.Errorf("(read) after a syntax error = %v, %v, want (c)", result, err)
+	}
+
+	for _, input := range []string{"(read)", `(read "a" "b")`, `(read-from-string "")`, `(read-from-string "(a")`, "(read-from-string 1)"} {
+		if _, err := interp.EvalString(context.Background(), input); err == nil {
+			t.Errorf("%s expected error", input)
+		}
+	}
+}
+
+// TestBuiltinPrint tests the builtinPrint function
+func TestBuiltinPrint(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispString{Value: "Hello"}}, &LispString{Value: "Hello"}},
+		{[]LispValue{&LispString{Value: "Factorial of 5 is 120"}}, &LispString{Value: "Factorial of 5 is 120"}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinPrint(env, test.args)
+		if err != nil {
+			t.Errorf("builtinPrint(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinAdd tests the builtinAdd function
+func TestBuiltinAdd(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispNumber{Value: 1}, &LispNumber{Value: 2}}, &LispNumber{Value: 3}},
+		{[]LispValue{&LispNumber{Value: 10}, &LispNumber{Value: 20}}, &LispNumber{Value: 30}},
+		{[]LispValue{&LispFloat{Value: 2.5}, &LispNumber{Value: 12}}, &LispFloat{Value: 14.5}},
+		{[]LispValue{&LispFloat{Value: 1.5}, &LispFloat{Value: 1.5}}, &LispFloat{Value: 3}},
+		{[]LispValue{&LispRational{Value: big.NewRat(1, 2)}, &LispRational{Value: big.NewRat(1, 2)}}, &LispNumber{Value: 1}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinAdd(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinAdd(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinSub tests the builtinSub function
+func TestBuiltinSub(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispNumber{Value: 10}, &LispNumber{Value: 5}}, &LispNumber{Value: 5}},
+		{[]LispValue{&LispNumber{Value: 20}, &LispNumber{Value: 10}, &LispNumber{Value: 5}}, &LispNumber{Value: 5}},
+		{[]LispValue{&LispNumber{Value: 3}, &LispFloat{Value: 6.5}}, &LispFloat{Value: -3.5}},
+		{[]LispValue{&LispNumber{Value: 7}}, &LispNumber{Value: -7}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinSub(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinSub(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinMul tests the builtinMul function
+func TestBuiltinMul(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispNumber{Value: 2}, &LispNumber{Value: 3}}, &LispNumber{Value: 6}},
+		{[]LispValue{&LispNumber{Value: 4}, &LispNumber{Value: 5}}, &LispNumber{Value: 20}},
+		{[]LispValue{&LispFloat{Value: -2.5}, &LispFloat{Value: -8}}, &LispFloat{Value: 20}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinMul(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinMul(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestIntegerOverflow tests that integer arithmetic promotes to big integers
+// at the int64 boundary
+func TestIntegerOverflow(t *testing.T) {
+	interp := NewInterpreter()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"(+ 9223372036854775807 1)", "9223372036854775808"},
+		{"(+ -9223372036854775807 -2)", "-9223372036854775809"},
+		{"(- -9223372036854775807 2)", "-9223372036854775809"},
+		{"(- 9223372036854775807 -1)", "9223372036854775808"},
+		{"(- (- -9223372036854775807 1))", "9223372036854775808"},
+		{"(* 9223372036854775807 2)", "18446744073709551614"},
+		{"(* -1 (- -9223372036854775807 1))", "9223372036854775808"},
+		{"(* 4294967296 4294967296)", "18446744073709551616"},
+		{"(/ (- -9223372036854775807 1) -1)", "9223372036854775808"},
+		{"(abs (- -9223372036854775807 1))", "9223372036854775808"},
+		{"(- (+ 9223372036854775807 1) 1)", "9223372036854775807"},
+		{"(+ 9223372036854775806 1)", "9223372036854775807"},
+		{"(* 3037000499 3037000499)", "9223372030926249001"},
+		{"(ceiling (pow 10 30))", "1000000000000000000000000000000"},
+		{"(floor (pow 10 30))", "1000000000000000000000000000000"},
+		{"(round (pow 10 30))", "1000000000000000000000000000000"},
+	}
+
+	for _, test := range tests {
+		result, err := interp.EvalString(context.Background(), test.input)
+		if err != nil || result.String() != test.expected {
+			t.Errorf("%s = %v, %v, want %s", test.input, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinDiv tests the builtinDiv function
+func TestBuiltinDiv(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+		err      string
+	}{
+		{[]LispValue{&LispNumber{Value: 10}, &LispNumber{Value: 2}}, &LispNumber{Value: 5}, ""},
+		{[]LispValue{&LispNumber{Value: 20}, &LispNumber{Value: 5}}, &LispNumber{Value: 4}, ""},
+		{[]LispValue{&LispNumber{Value: 10}, &LispNumber{Value: 0}}, nil, "division by zero"},
+		{[]LispValue{&LispFloat{Value: -10}, &LispFloat{Value: -2}}, &LispFloat{Value: 5}, ""},
+		{[]LispValue{&LispNumber{Value: 1}, &LispNumber{Value: 3}}, &LispRational{Value: big.NewRat(1, 3)}, ""},
+		{[]LispValue{&LispNumber{Value: 4}}, &LispRational{Value: big.NewRat(1, 4)}, ""},
+	}
+
+	for _, test := range tests {
+		result, err := builtinDiv(env, test.args)
+		if (err != nil && err.Error() != test.err) || (err == nil && !lispValueEqual(result, test.expected)) {
+			t.Errorf("builtinDiv(%v) = %v, %v, want %v, %v", test.args, result, err, test.expected, test.err)
+		}
+	}
+}
+
+// TestBuiltinMod tests the builtinMod function
+func TestBuiltinMod(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispNumber{Value: 10}, &LispNumber{Value: 2}}, &LispNumber{Value: 0}},
+		{[]LispValue{&LispNumber{Value: 10}, &LispNumber{Value: 6}}, &LispNumber{Value: 4}},
+		{[]LispValue{&LispNumber{Value: -7}, &LispNumber{Value: 2}}, &LispNumber{Value: -1}},
+		{[]LispValue{&LispFloat{Value: 7.5}, &LispNumber{Value: 2}}, &LispFloat{Value: 1.5}},
+		{[]LispValue{&LispNumber{Value: 7}, &LispFloat{Value: -2.5}}, &LispFloat{Value: 2}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinMod(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinMod(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+
+	exactTests := []struct {
+		input    string
+		expected string
+	}{
+		{"(% 18446744073709551614 2)", "0"},
+		{"(% 18446744073709551615 10)", "5"},
+		{"(% (- 0 18446744073709551615) 10)", "-5"},
+		{"(% 7/2 1)", "1/2"},
+		{"(% -7/2 1)", "-1/2"},
+		{"(% 7 3/2)", "1"},
+	}
+	for _, test := range exactTests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || result.String() != test.expected {
+			t.Errorf("Eval(%s) = %v, %v, want %s", test.input, result, err, test.expected)
+		}
+	}
+
+	for _, input := range []string{"(% 1 0)", "(% 1.5 0)", "(% 1/2 0)", `(% "a" 2)`} {
+		if _, err := Eval(env, parseExpr(t, input)); err == nil {
+			t.Errorf("Eval(%s) expected error", input)
+		}
+	}
+}
+
+// TestBuiltinMod tests the builtinPow function
+func TestBuiltinPow(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispNumber{Value: 10}, &LispNumber{Value: 2}}, &LispNumber{Value: 100}},
+		{[]LispValue{&LispNumber{Value: 8}, &LispNumber{Value: 3}}, &LispNumber{Value: 512}},
+		{[]LispValue{&LispNumber{Value: -2}, &LispNumber{Value: 4}}, &LispNumber{Value: 16}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinPow(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinPow(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinSqrt tests the builtinSqrt function
+func TestBuiltinSqrt(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispNumber{Value: 64}}, &LispNumber{Value: 8}},
+		{[]LispValue{&LispNumber{Value: 4}}, &LispNumber{Value: 2}},
+		{[]LispValue{&LispFloat{Value: 2}}, &LispFloat{Value: 1.4142135623730951}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinSqrt(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinSqrt(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinFloatFunction tests the builtinFloatFunction function
+func TestBuiltinFloatFunction(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		name     string
+		arg      LispValue
+		expected LispValue
+	}{
+		{SIN, &LispNumber{Value: 0}, &LispFloat{Value: 0}},
+		{COS, &LispNumber{Value: 0}, &LispFloat{Value: 1}},
+		{EXP, &LispNumber{Value: 0}, &LispFloat{Value: 1}},
+		{LOG10, &LispNumber{Value: 1000}, &LispFloat{Value: 3}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinFloatFunction(env, []LispValue{test.arg}, test.name)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinFloatFunction(%v, %s) = %v, %v, want %v", test.arg, test.name, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinGcdLcm tests the builtinGcdLcm function
+func TestBuiltinGcdLcm(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		name     string
+		args     []LispValue
+		expected LispValue
+	}{
+		{GCD, []LispValue{&LispNumber{Value: 12}, &LispNumber{Value: 18}, &LispNumber{Value: -24}}, &LispNumber{Value: 6}},
+		{GCD, []LispValue{}, &LispNumber{Value: 0}},
+		{LCM, []LispValue{&LispNumber{Value: 4}, &LispNumber{Value: 6}}, &LispNumber{Value: 12}},
+		{LCM, []LispValue{}, &LispNumber{Value: 1}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinGcdLcm(env, test.args, test.name)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinGcdLcm(%v, %s) = %v, %v, want %v", test.args, test.name, result, err, test.expected)
+		}
+	}
+
+	if _, err := builtinGcdLcm(env, []LispValue{&LispFloat{Value: 1.5}}, GCD); err == nil {
+		t.Errorf("expected error for non integer argument to gcd")
+	}
+}
+
+// TestBuiltinIntegerDivision tests the builtinIntegerDivision function
+func TestBuiltinIntegerDivision(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		name     string
+		args     []LispValue
+		expected LispValue
+	}{
+		{QUOTIENT, []LispValue{&LispNumber{Value: -7}, &LispNumber{Value: 2}}, &LispNumber{Value: -3}},
+		{REMAINDER, []LispValue{&LispNumber{Value: -7}, &LispNumber{Value: 2}}, &LispNumber{Value: -1}},
+		{MODULO, []LispValue{&LispNumber{Value: -7}, &LispNumber{Value: 2}}, &LispNumber{Value: 1}},
+		{MODULO, []LispValue{&LispNumber{Value: 7}, &LispNumber{Value: -2}}, &LispNumber{Value: -1}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinIntegerDivision(env, test.args, test.name)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinIntegerDivision(%v, %s) = %v, %v, want %v", test.args, test.name, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinBitwise tests the builtinBitwise, builtinLognot and builtinAsh functions
+func TestBuiltinBitwise(t *testing.T) {
+	env := newTestEnvironment()
+	twelve, ten := &LispNumber{Value: 12}, &LispNumber{Value: 10}
+	minusEight, minusOne := &LispNumber{Value: -8}, &LispNumber{Value: -1}
+
+	tests := []struct {
+		call     func() (LispValue, error)
+		expected LispValue
+	}{
+		{func() (LispValue, error) { return builtinBitwise(env, []LispValue{twelve, ten}, LOGAND) }, &LispNumber{Value: 8}},
+		{func() (LispValue, error) { return builtinBitwise(env, []LispValue{twelve, ten}, LOGIOR) }, &LispNumber{Value: 14}},
+		{func() (LispValue, error) { return builtinBitwise(env, []LispValue{twelve, ten}, LOGXOR) }, &LispNumber{Value: 6}},
+		{func() (LispValue, error) { return builtinLognot(env, []LispValue{&LispNumber{Value: 5}}) }, &LispNumber{Value: -6}},
+		{func() (LispValue, error) { return builtinAsh(env, []LispValue{&LispNumber{Value: 1}, ten}) }, &LispNumber{Value: 1024}},
+		{func() (LispValue, error) { return builtinAsh(env, []LispValue{minusEight, minusOne}) }, &LispNumber{Value: -4}},
+	}
+
+	for i, test := range tests {
+		result, err := test.call()
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("case %d = %v, %v, want %v", i, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinRandom tests the builtinRandom function
+func TestBuiltinRandom(t *testing.T) {
+	env := newTestEnvironment()
+	limits := []LispValue{&LispNumber{Value: 10}, &LispFloat{Value: 1.5}}
+
+	interpreterOf(env).Seed(42)
+	first := make([]LispValue, 0, len(limits))
+	for _, limit := range limits {
+		result, err := builtinRandom(env, []LispValue{limit})
+		if err != nil {
+			t.Fatalf("builtinRandom(%v) returned error: %v", limit, err)
+		}
+		if cmp, _ := compareNumbers(result, limit); cmp >= 0 || toFloat(result) < 0 {
+			t.Errorf("builtinRandom(%v) = %v, out of range", limit, result)
+		}
+		first = append(first, result)
+	}
+
+	interpreterOf(env).Seed(42)
+	for i, limit := range limits {
+		result, _ := builtinRandom(env, []LispValue{limit})
+		if !lispValueEqual(result, first[i]) {
+			t.Errorf("builtinRandom(%v) after reseeding = %v, want %v", limit, result, first[i])
+		}
+	}
+
+	if _, err := builtinRandom(env, []LispValue{&LispNumber{Value: 0}}); err == nil {
+		t.Errorf("expected error for non positive limit")
+	}
+}
+
+// TestBuiltinShuffle tests the builtinShuffle function
+func TestBuiltinShuffle(t *testing.T) {
+	env := newTestEnvironment()
+	env["s"] = &LispRandomState{State: 7}
+	list := &LispList{Elements: []LispValue{&LispAtom{Value: "list"}, &LispNumber{Value: 1}, &LispNumber{Value: 2}, &LispNumber{Value: 3}, &LispNumber{Value: 4}}}
+
+	result, err := builtinShuffle(env, []LispValue{list, &LispAtom{Value: "s"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	env["s"] = &LispRandomState{State: 7}
+	again, _ := builtinShuffle(env, []LispValue{list, &LispAtom{Value: "s"}})
+	if !lispValueEqual(result, again) {
+		t.Errorf("shuffle with the same random state = %v, then %v", result, again)
+	}
+
+	sum := 0
+	for _, elem := range result.(*LispList).Elements {
+		sum += elem.(*LispNumber).Value
+	}
+	if len(result.(*LispList).Elements) != 4 || sum != 10 {
+		t.Errorf("builtinShuffle(%v) = %v, elements changed", list, result)
+	}
+}
+
+// TestBuiltinConcat tests the builtinConcat function
+func TestBuiltinConcat(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispString{Value: "Hello "}, &LispString{Value: "World!"}}, &LispString{Value: "Hello World!"}},
+		{[]LispValue{&LispString{Value: "One "}, &LispString{Value: "Piece"}}, &LispString{Value: "One Piece"}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinConcat(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinConcat(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinSubstring tests the builtinSubstring function
+func TestBuiltinSubstring(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispString{Value: "Hello World!"}, &LispNumber{Value: 0}, &LispNumber{Value: 5}}, &LispString{Value: "Hello"}},
+		{[]LispValue{&LispString{Value: "Hello World!"}, &LispNumber{Value: 6}, &LispNumber{Value: 12}}, &LispString{Value: "World!"}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinSubstring(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinSubstring(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinIsNumber tests the builtinIsNumber function
+func TestBuiltinIsNumber(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispNumber{Value: 12}}, &LispBoolean{Value: true}},
+		{[]LispValue{&LispFloat{Value: 12.5}}, &LispBoolean{Value: true}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinIsNumber(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinIsNumber(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinIsString tests the builtinIsString function
+func TestBuiltinIsString(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispString{Value: "Hello"}}, &LispBoolean{Value: true}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinIsString(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinIsString(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinLt tests the builtinLt function
+func TestBuiltinLt(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispNumber{Value: 1}, &LispNumber{Value: 2}}, &LispAtom{Value: "true"}},
+		{[]LispValue{&LispNumber{Value: 3}, &LispNumber{Value: 2}}, &LispAtom{Value: "false"}},
+		{[]LispValue{&LispNumber{Value: 1}, &LispFloat{Value: 1.5}, &LispNumber{Value: 2}}, &LispAtom{Value: "true"}},
+		{[]LispValue{&LispNumber{Value: 1}, &LispNumber{Value: 3}, &LispNumber{Value: 2}}, &LispAtom{Value: "false"}},
+		{[]LispValue{&LispRational{Value: big.NewRat(1, 3)}, &LispFloat{Value: 0.5}}, &LispAtom{Value: "true"}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinLt(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinLt(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinLtOrEq tests the builtinLtOrEq function
+func TestBuiltinLtOrEq(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispNumber{Value: 1}, &LispNumber{Value: 2}}, &LispAtom{Value: "true"}},
+		{[]LispValue{&LispNumber{Value: 3}, &LispNumber{Value: 2}}, &LispAtom{Value: "false"}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinLtOrEq(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinLtOrEq(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinGt tests the builtinGt function
+func TestBuiltinGt(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispNumber{Value: 3}, &LispNumber{Value: 2}}, &LispAtom{Value: "true"}},
+		{[]LispValue{&LispNumber{Value: 1}, &LispNumber{Value: 2}}, &LispAtom{Value: "false"}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinGt(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinGt(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinGtOrEq tests the builtinGtOrEq function
+func TestBuiltinGtOrEq(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispNumber{Value: 3}, &LispNumber{Value: 2}}, &LispAtom{Value: "true"}},
+		{[]LispValue{&LispNumber{Value: 1}, &LispNumber{Value: 2}}, &LispAtom{Value: "false"}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinGtOrEq(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinGt(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinEq tests the builtinEq function
+func TestBuiltinEq(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispNumber{Value: 2}, &LispNumber{Value: 2}}, &LispAtom{Value: "true"}},
+		{[]LispValue{&LispNumber{Value: 2}, &LispNumber{Value: 3}}, &LispAtom{Value: "false"}},
+		{[]LispValue{&LispNumber{Value: 2}, &LispFloat{Value: 2}, &LispNumber{Value: 2}}, &LispAtom{Value: "true"}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinEq(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinEq(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinNumNotEq tests the builtinNumNotEq function
+func TestBuiltinNumNotEq(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispNumber{Value: 1}, &LispNumber{Value: 2}, &LispNumber{Value: 3}}, &LispAtom{Value: "true"}},
+		{[]LispValue{&LispNumber{Value: 1}, &LispNumber{Value: 2}, &LispFloat{Value: 1}}, &LispAtom{Value: "false"}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinNumNotEq(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinNumNotEq(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinRound tests the builtinRound function
+func TestBuiltinRound(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		mode     string
+		arg      LispValue
+		expected LispValue
+	}{
+		{FLOOR, &LispRational{Value: big.NewRat(-7, 2)}, &LispNumber{Value: -4}},
+		{CEILING, &LispRational{Value: big.NewRat(-7, 2)}, &LispNumber{Value: -3}},
+		{ROUND, &LispRational{Value: big.NewRat(7, 2)}, &LispNumber{Value: 4}},
+		{ROUND, &LispRational{Value: big.NewRat(5, 2)}, &LispNumber{Value: 2}},
+		{TRUNCATE, &LispRational{Value: big.NewRat(-7, 2)}, &LispNumber{Value: -3}},
+		{FLOOR, &LispFloat{Value: 2.5}, &LispFloat{Value: 2}},
+		{ROUND, &LispFloat{Value: 2.5}, &LispFloat{Value: 2}},
+		{CEILING, &LispNumber{Value: 3}, &LispNumber{Value: 3}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinRound(env, []LispValue{test.arg}, test.mode)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinRound(%v, %s) = %v, %v, want %v", test.arg, test.mode, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinMinMax tests the builtinMinMax function
+func TestBuiltinMinMax(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		name     string
+		args     []LispValue
+		expected LispValue
+	}{
+		{MIN, []LispValue{&LispNumber{Value: 3}, &LispNumber{Value: 1}, &LispNumber{Value: 2}}, &LispNumber{Value: 1}},
+		{MAX, []LispValue{&LispNumber{Value: 3}, &LispNumber{Value: 1}, &LispNumber{Value: 2}}, &LispNumber{Value: 3}},
+		{MAX, []LispValue{&LispNumber{Value: 3}, &LispFloat{Value: 1.5}}, &LispFloat{Value: 3}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinMinMax(env, test.args, test.name)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinMinMax(%v, %s) = %v, %v, want %v", test.args, test.name, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinIsExact tests the builtinIsExact function
+func TestBuiltinIsExact(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		name     string
+		arg      LispValue
+		expected LispValue
+	}{
+		{EXACT, &LispNumber{Value: 3}, &LispBoolean{Value: true}},
+		{EXACT, &LispRational{Value: big.NewRat(1, 3)}, &LispBoolean{Value: true}},
+		{EXACT, &LispFloat{Value: 3}, &LispBoolean{Value: false}},
+		{INEXACT, &LispFloat{Value: 3}, &LispBoolean{Value: true}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinIsExact(env, []LispValue{test.arg}, test.name)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinIsExact(%v, %s) = %v, %v, want %v", test.arg, test.name, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinDefun tests the builtinDefun function
+func TestBuiltinDefun(t *testing.T) {
+	env := make(Environment)
+
+	// Test case 1: Correct input
+	name := &LispAtom{Value: "my-func"}
+	params := &LispList{Elements: []LispValue{&LispAtom{Value: "x"}, &LispAtom{Value: "y"}}}
+	body := &LispAtom{Value: "body"}
+
+	args := []LispValue{name, params, body}
+
+	result, err := builtinDefun(env, args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fn, ok := result.(*LispFunction); !ok {
+		t.Fatalf("expected *LispFunction, got %T", result)
+	} else {
+		if len(fn.Params) != 2 || fn.Params[0].String() != "x" || fn.Params[1].String() != "y" {
+			t.Errorf("unexpected function parameters: %v", fn.Params)
+		}
+		if fn.Body.String() != "body" {
+			t.Errorf("unexpected function body: %v", fn.Body)
+		}
+	}
+
+	if env[name.Value] != result {
+		t.Errorf("function not correctly added to environment")
+	}
+
+	// Test case 2: Incorrect number of arguments
+	args = []LispValue{name, params}
+	_, err = builtinDefun(env, args)
+	if err == nil || err.Error() != "wrong number of arguments to defun" {
+		t.Errorf("expected error for wrong number of arguments, got: %v", err)
+	}
+
+	// Test case 3: Invalid function name
+	args = []LispValue{params, params, body}
+	_, err = builtinDefun(env, args)
+	if err == nil || err.Error() != "invalid function name: (x y)" {
+		t.Errorf("expected error for invalid function name, got: %v", err)
+	}
+
+	// Test case 4: Invalid function parameters
+	args = []LispValue{name, body, body}
+	_, err = builtinDefun(env, args)
+	if err == nil || err.Error() != "invalid function parameters: body" {
+		t.Errorf("expected error for invalid function parameters, got: %v", err)
+	}
+
+	// Test case 5: a function named like a builtin shadows it, unless the
+	// builtin is a special form
+	interp := NewInterpreter()
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"(defun abs (x) 42) (abs -7)", "42"},
+		{"(defun if (a b c) 0) (if (< 1 2) 1 2)", "1"},
+	}
+	for _, test := range tests {
+		result, err := interp.EvalString(context.Background(), test.input)
+		if err != nil || result.String() != test.expected {
+			t.Errorf("%s = %v, %v, want %s", test.input, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinLambda tests the builtinLambda function
+func TestBuiltinLambda(t *testing.T) {
+	env := newTestEnvironment()
+
+	// Valid test case
+	params := &LispList{Elements: []LispValue{&LispAtom{Value: "x"}, &LispAtom{Value: "y"}}}
+	body := &LispAtom{Value: "x + y"}
+	args := []LispValue{params, body}
+	expected := &LispFunction{Params: params.Elements, Body: body, Env: env}
+
+	result, err := builtinLambda(env, args)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result.String() != expected.String() {
+		t.Errorf("expected %v, got %v", expected.String(), result.String())
+	}
+
+	// Invalid number of arguments
+	invalidArgs := []LispValue{params}
+	_, err = builtinLambda(env, invalidArgs)
+	if err == nil || err.Error() != "wrong number of arguments to lambda" {
+		t.Errorf("expected 'wrong number of arguments to lambda', got %v", err)
+	}
+
+	// Invalid parameter type
+	invalidParams := []LispValue{&LispAtom{Value: "x"}, body}
+	_, err = builtinLambda(env, invalidParams)
+	if err == nil || err.Error() != fmt.Sprintf("invalid lambda parameters: %v", invalidParams[0]) {
+		t.Errorf("expected 'invalid lambda parameters: %v', got %v", invalidParams[0], err)
+	}
+}
+
+// TestBuiltinAnd tests the builtinAnd function
+func TestBuiltinAnd(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispString{Value: "true"}, &LispString{Value: "false"}}, &LispAtom{Value: "false"}},
+		{[]LispValue{&LispString{Value: "true"}, &LispString{Value: "true"}}, &LispAtom{Value: "true"}},
+		{[]LispValue{&LispString{Value: "false"}, &LispString{Value: "false"}}, &LispAtom{Value: "false"}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinAnd(env, test.args)
+		if err != nil {
+			t.Errorf("builtinAnd(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinOr tests the builtinOr function
+func TestBuiltinOr(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispString{Value: "true"}, &LispString{Value: "false"}}, &LispAtom{Value: "true"}},
+		{[]LispValue{&LispString{Value: "true"}, &LispString{Value: "true"}}, &LispAtom{Value: "true"}},
+		{[]LispValue{&LispString{Value: "false"}, &LispString{Value: "false"}}, &LispAtom{Value: "false"}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinOr(env, test.args)
+		if err != nil {
+			t.Errorf("builtinOr(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinNot tests the builtinNot function
+func TestBuiltinNot(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispString{Value: "true"}}, &LispAtom{Value: "false"}},
+		{[]LispValue{&LispString{Value: "false"}}, &LispAtom{Value: "true"}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinNot(env, test.args)
+		if err != nil {
+			t.Errorf("builtinNot(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinCar tests the builtinCar function
+func TestBuiltinCar(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispList{Elements: []LispValue{&LispAtom{Value: "list"}, &LispNumber{Value: 1}, &LispNumber{Value: 2}, &LispNumber{Value: 3}}}}, &LispNumber{Value: 1}},
+		{[]LispValue{&LispList{Elements: []LispValue{&LispAtom{Value: "list"}, &LispNumber{Value: 2}, &LispNumber{Value: 4}, &LispNumber{Value: 6}}}}, &LispNumber{Value: 1}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinCar(env, test.args)
+		if err != nil {
+			t.Errorf("builtinCar(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinCdr tests the builtinCdr function
+func TestBuiltinCdr(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispList{Elements: []LispValue{&LispAtom{Value: "list"}, &LispNumber{Value: 1}, &LispNumber{Value: 2}, &LispNumber{Value: 3}}}}, &LispList{Elements: []LispValue{&LispNumber{Value: 2}, &LispNumber{Value: 3}}}},
+		{[]LispValue{&LispList{Elements: []LispValue{&LispAtom{Value: "list"}, &LispNumber{Value: 2}, &LispNumber{Value: 4}, &LispNumber{Value: 6}}}}, &LispList{Elements: []LispValue{&LispNumber{Value: 4}, &LispNumber{Value: 6}}}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinCdr(env, test.args)
+		if err != nil {
+			t.Errorf("builtinCdr(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinCons tests the builtinCons function
+func TestBuiltinCons(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispNumber{Value: 0}, &LispList{Elements: []LispValue{&LispAtom{Value: "list"}, &LispNumber{Value: 1}, &LispNumber{Value: 2}, &LispNumber{Value: 3}}}}, &LispList{Elements: []LispValue{&LispNumber{Value: 0}, &LispNumber{Value: 1}, &LispNumber{Value: 2}, &LispNumber{Value: 3}}}},
+		{[]LispValue{&LispNumber{Value: 0}, &LispList{Elements: []LispValue{&LispAtom{Value: "list"}, &LispNumber{Value: 2}, &LispNumber{Value: 4}, &LispNumber{Value: 6}}}}, &LispList{Elements: []LispValue{&LispNumber{Value: 0}, &LispNumber{Value: 2}, &LispNumber{Value: 4}, &LispNumber{Value: 6}}}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinCons(env, test.args)
+		if err != nil {
+			t.Errorf("builtinCons(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinLength tests the builtinLength function
+func TestBuiltinLength(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispList{Elements: []LispValue{&LispAtom{Value: "list"}, &LispNumber{Value: 1}, &LispNumber{Value: 2}, &LispNumber{Value: 3}}}}, &LispNumber{Value: 3}},
+		{[]LispValue{&LispList{Elements: []LispValue{&LispAtom{Value: "list"}, &LispNumber{Value: 0}, &LispNumber{Value: 2}, &LispNumber{Value: 4}, &LispNumber{Value: 6}}}}, &LispNumber{Value: 4}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinLength(env, test.args)
+		if err != nil {
+			t.Errorf("builtinLength(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinAppend tests the builtinAppend function
+func TestBuiltinAppend(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{&LispList{Elements: []LispValue{&LispAtom{Value: "list"}, &LispNumber{Value: 1}, &LispNumber{Value: 2}, &LispNumber{Value: 3}}}, &LispList{Elements: []LispValue{&LispAtom{Value: "list"}, &LispNumber{Value: 10}, &LispNumber{Value: 11}, &LispNumber{Value: 12}}}}, &LispList{Elements: []LispValue{&LispNumber{Value: 1}, &LispNumber{Value: 2}, &LispNumber{Value: 3}, &LispNumber{Value: 10}, &LispNumber{Value: 11}, &LispNumber{Value: 12}}}},
+		{[]LispValue{&LispList{Elements: []LispValue{&LispAtom{Value: "list"}, &LispNumber{Value: 4}, &LispNumber{Value: 6}, &LispNumber{Value: 8}}}, &LispList{Elements: []LispValue{&LispAtom{Value: "list"}, &LispNumber{Value: 20}, &LispNumber{Value: 24}, &LispNumber{Value: 30}}}}, &LispList{Elements: []LispValue{&LispNumber{Value: 4}, &LispNumber{Value: 6}, &LispNumber{Value: 8}, &LispNumber{Value: 20}, &LispNumber{Value: 24}, &LispNumber{Value: 30}}}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinAppend(env, test.args)
+		if err != nil {
+			t.Errorf("builtinAppend(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinError tests the builtinError function
+func TestBuiltinError(t *testing.T) {
+	env := newTestEnvironment()
+
+	_, err := builtinError(env, []LispValue{&LispString{Value: "bad record"}, &LispNumber{Value: 42}})
+	condErr, ok := err.(*ConditionError)
+	if !ok {
+		t.Fatalf("expected *ConditionError, got %T: %v", err, err)
+	}
+	c := condErr.Condition
+	if c.Type != SIMPLE_ERROR_TYPE || c.Message != "bad record" || !lispValueEqual(c.Irritants, []LispValue{&LispNumber{Value: 42}}) {
+		t.Errorf("unexpected condition: %v", c)
+	}
+
+	_, err = Eval(env, parseExpr(t, "\n  (error \"bad record\")"))
+	if c := conditionFromError(err); c.Line != 2 || c.Column != 3 {
+		t.Errorf("expected condition at line 2, column 3, got %v", err)
+	}
+}
+
+// TestBuiltinHandlerCase tests the builtinHandlerCase function
+func TestBuiltinHandlerCase(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		input    string
+		expected LispValue
+	}{
+		{`(handler-case (+ 1 2) (error (c) "handled"))`, &LispNumber{Value: 3}},
+		{`(handler-case (/ 1 0) (error (c) (condition-message c)))`, &LispString{Value: "division by zero"}},
+		{`(handler-case (error "bad" 1) (simple-error (c) (condition-irritants c)))`, &LispList{Elements: []LispValue{&LispNumber{Value: 1}}}},
+		{`(handler-case (error "bad") (condition () "any condition"))`, &LispString{Value: "any condition"}},
+		{`(handler-case (handler-case (error "inner") (simple-error (c) (error c))) (error (c) (condition-message c)))`, &LispString{Value: "inner"}},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	if _, err := Eval(env, parseExpr(t, `(handler-case (error "bad") (division-by-zero (c) 0))`)); err == nil {
+		t.Errorf("expected unhandled condition to propagate")
+	}
+}
+
+// TestBuiltinGuard tests the builtinGuard function
+func TestBuiltinGuard(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		input    string
+		expected LispValue
+	}{
+		{`(guard (e ((error-object? e) (error-object-message e))) (error "boom"))`, &LispString{Value: "boom"}},
+		{`(guard (e ((= 1 2) "no") (else "fallback")) (error "boom"))`, &LispString{Value: "fallback"}},
+		{`(guard (e (else "fallback")) 1 2)`, &LispNumber{Value: 2}},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	if _, err := Eval(env, parseExpr(t, `(guard (e ((= 1 2) "no")) (error "boom"))`)); err == nil {
+		t.Errorf("expected error to be signalled again when no clause applies")
+	}
+}
+
+// TestBuiltinIgnoreErrors tests the builtinIgnoreErrors function
+func TestBuiltinIgnoreErrors(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		args     []LispValue
+		expected LispValue
+	}{
+		{[]LispValue{parseExpr(t, "(car 5)")}, &LispNil{}},
+		{[]LispValue{parseExpr(t, "(+ 1 2)"), parseExpr(t, "(* 2 3)")}, &LispNumber{Value: 6}},
+	}
+
+	for _, test := range tests {
+		result, err := builtinIgnoreErrors(env, test.args)
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("builtinIgnoreErrors(%v) = %v, %v, want %v", test.args, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinHandlerBind tests the builtinHandlerBind function
+func TestBuiltinHandlerBind(t *testing.T) {
+	env := newTestEnvironment()
+	definitions := []string{
+		`(defun use-five (c) (invoke-restart 'use-value 5))`,
+		`(define-record-type <box> (box v) box? (v unbox))`,
+	}
+	for _, definition := range definitions {
+		if _, err := Eval(env, parseExpr(t, definition)); err != nil {
+			t.Fatalf("Eval(%s) = %v", definition, err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected LispValue
+	}{
+		{`(handler-bind ((error (lambda (c) 0))) (+ 1 2))`, &LispNumber{Value: 3}},
+		{`(handler-case (handler-bind ((error (lambda (c) 0))) (error "boom")) (error (c) "declined"))`, &LispString{Value: "declined"}},
+		{`(restart-case (handler-bind ((error (lambda (c) (invoke-restart 'use-value 42)))) (error "boom")) (use-value (v) v))`, &LispNumber{Value: 42}},
+		{`(handler-bind ((error (lambda (c) (invoke-restart 'use-value 5)))) (+ 1 (restart-case (car 5) (use-value (v) v))))`, &LispNumber{Value: 6}},
+		{`(handler-case (restart-case (handler-bind ((error (lambda (c) (invoke-restart 'use 7)))) undefined-var) (use (v) v)) (error (c) "caught"))`, &LispNumber{Value: 7}},
+		{`(handler-case undefined-var (error (c) (condition-message c)))`, &LispString{Value: "unbound symbol: undefined-var"}},
+		{`(handler-bind ((warning 'muffle-warning)) (warn "careful") 7)`, &LispNumber{Value: 7}},
+		{`(restart-case (handler-bind ((error 'use-five)) (car 5)) (use-value (v) v))`, &LispNumber{Value: 5}},
+		{`(handler-case (handler-bind ((error box?)) (error "boom")) (error (c) "declined"))`, &LispString{Value: "declined"}},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinRestartCase tests the builtinRestartCase function
+func TestBuiltinRestartCase(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		input    string
+		expected LispValue
+	}{
+		{`(restart-case (+ 1 2) (abort () 0))`, &LispNumber{Value: 3}},
+		{`(restart-case (invoke-restart 'retry 1 2) (retry (a b) (+ a b)))`, &LispNumber{Value: 3}},
+		{`(restart-case (restart-case (compute-restarts) (retry () 0)) (abort () :report "Give up" 1))`, &LispList{Elements: []LispValue{&LispAtom{Value: "retry"}, &LispAtom{Value: "abort"}}}},
+		{`(restart-case (compute-restarts) (foo () 1) (bar (x) 2))`, &LispList{Elements: []LispValue{&LispAtom{Value: "foo"}, &LispAtom{Value: "bar"}}}},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	if _, err := Eval(env, parseExpr(t, `(invoke-restart 'retry)`)); err == nil {
+		t.Errorf("expected error invoking an inactive restart")
+	}
+}
+
+// TestBuiltinDefineCondition tests the builtinDefineCondition function
+func TestBuiltinDefineCondition(t *testing.T) {
+	env := newTestEnvironment()
+	definitions := []string{
+		`(define-condition file-problem (error) ((path :initarg :path :reader file-problem-path)) (:report "file problem"))`,
+		`(define-condition low-disk (warning) ((level :initform (* 2 5))))`,
+	}
+	for _, definition := range definitions {
+		if _, err := Eval(env, parseExpr(t, definition)); err != nil {
+			t.Fatalf("Eval(%s) failed: %v", definition, err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected LispValue
+	}{
+		{`(handler-case (error 'file-problem :path "/tmp/x") (file-problem (c) (file-problem-path c)))`, &LispString{Value: "/tmp/x"}},
+		{`(handler-case (error 'file-problem :path "/tmp/x") (error (c) (condition-message c)))`, &LispString{Value: "file problem"}},
+		{`(condition-slot (make-condition 'low-disk) 'level)`, &LispNumber{Value: 10}},
+		{`(condition-type (make-condition 'low-disk :level 1))`, &LispAtom{Value: "low-disk"}},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+}
+
+// TestBuiltinSignal tests the builtinSignal and builtinWarn functions
+func TestBuiltinSignal(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		input    string
+		expected LispValue
+	}{
+		{`(signal "note")`, &LispNil{}},
+		{`(handler-case (signal "note") (error (c) 1))`, &LispNil{}},
+		{`(handler-case (signal "note") (condition (c) (condition-type c)))`, &LispAtom{Value: "simple-condition"}},
+		{`(handler-bind ((warning (lambda (c) (muffle-warning c)))) (warn "careful") 7)`, &LispNumber{Value: 7}},
+		{`(handler-case (warn "careful") (warning (c) (condition-message c)))`, &LispString{Value: "careful"}},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	if _, err := Eval(env, parseExpr(t, `(muffle-warning)`)); err == nil {
+		t.Errorf("expected error muffling outside of warn")
+	}
+
+	interp := NewInterpreter()
+	var output strings.Builder
+	interp.SetErrorOutput(&output)
+	if _, err := interp.EvalString(context.Background(), `(warn "low on disk")`); err != nil {
+		t.Fatalf("EvalString(warn) = %v", err)
+	}
+	if output.String() != "Warning: low on disk\n" {
+		t.Errorf("warn printed %q to the error port, want %q", output.String(), "Warning: low on disk\n")
+	}
+}
+
+// panicValue is a value whose printing panics, to exercise the Go panic paths
+type panicValue struct{}
+
+func (panicValue) String() string {
+	panic("panicValue printed")
+}
+
+// TestBuiltinUnwindProtect tests the builtinUnwindProtect function
+func TestBuiltinUnwindProtect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected LispValue
+	}{
+		{`(unwind-protect (+ 1 2) (defun cleaned () 1))`, &LispNumber{Value: 3}},
+		{`(handler-case (unwind-protect (car 5) (defun cleaned () 1)) (error (c) "caught"))`, &LispString{Value: "caught"}},
+		{`(restart-case (unwind-protect (invoke-restart 'skip) (defun cleaned () 1)) (skip () 0))`, &LispNumber{Value: 0}},
+		{`(handler-case (unwind-protect 1 (defun cleaned () 1) (error "cleanup failed")) (error (c) (condition-message c)))`, &LispString{Value: "cleanup failed"}},
+	}
+
+	for _, test := range tests {
+		env := newTestEnvironment()
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+		if _, ok := env["cleaned"]; !ok {
+			t.Errorf("Eval(%s) did not run the cleanup forms", test.input)
+		}
+	}
+
+	env := newTestEnvironment()
+	env["p"] = panicValue{}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected the panic to keep unwinding")
+			}
+		}()
+		Eval(env, parseExpr(t, `(unwind-protect (error p) (defun cleaned () 1))`))
+	}()
+	if _, ok := env["cleaned"]; !ok {
+		t.Errorf("cleanup forms did not run on panic")
+	}
+
+	interp := NewInterpreter()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	src := `(defvar *cleaned* 0) (defun spin (n) (spin (+ n 1))) (unwind-protect (spin 0) (defparameter *cleaned* (+ *cleaned* 1)))`
+	var cancelled *CancelledError
+	if _, err := interp.EvalString(ctx, src); !errors.As(err, &cancelled) {
+		t.Errorf("EvalString(%s) = %v, want a CancelledError", src, err)
+	}
+	if cleaned, _ := interp.Lookup("*cleaned*"); cleaned.String() != "1" {
+		t.Errorf("cleanup forms ran %v times on cancellation, want 1", cleaned)
+	}
+}
+
+// TestBuiltinDynamicWind tests the builtinDynamicWind function
+func TestBuiltinDynamicWind(t *testing.T) {
+	env := newTestEnvironment()
+	ticks := 0
+	if err := interpreterOf(env).RegisterGoFunc("tick", func() int { ticks++; return ticks }, ""); err != nil {
+		t.Fatalf("RegisterGoFunc(tick) failed: %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected LispValue
+	}{
+		{`(dynamic-wind (lambda () 1) (lambda () 2) (lambda () 3))`, &LispNumber{Value: 2}},
+		{`(handler-case (dynamic-wind (lambda () 1) (lambda () (car 5)) (lambda () (error "after ran"))) (error (c) (condition-message c)))`, &LispString{Value: "after ran"}},
+		{`(dynamic-wind 'tick 'tick 'tick)`, &LispNumber{Value: 2}},
+		{`(dynamic-wind (make-parameter 1) (make-parameter 2) (lambda () 3))`, &LispNumber{Value: 2}},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	if _, err := Eval(env, parseExpr(t, `(dynamic-wind 1 2 3)`)); err == nil {
+		t.Errorf("expected error for non-function arguments")
+	}
+}
+
+// TestBuiltinCatch tests the builtinCatch and builtinThrow functions
+func TestBuiltinCatch(t *testing.T) {
+	env := newTestEnvironment()
+	if _, err := Eval(env, parseExpr(t, `(defun search (n) (if (= n 3) (throw 'found n) (search (+ n 1))))`)); err != nil {
+		t.Fatalf("defun failed: %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected LispValue
+	}{
+		{`(catch 'done (+ 1 2))`, &LispNumber{Value: 3}},
+		{`(catch 'found (search 0) "not found")`, &LispNumber{Value: 3}},
+		{`(catch 'outer (catch 'inner (throw 'outer 1)) 2)`, &LispNumber{Value: 1}},
+		{`(catch 'done (unwind-protect (throw 'done 1) (defun cleaned () 1)))`, &LispNumber{Value: 1}},
+		{`(catch 'done (handler-case (throw 'done 1) (error (c) 2)))`, &LispNumber{Value: 1}},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	if _, err := Eval(env, parseExpr(t, `(throw 'nowhere 1)`)); err == nil {
+		t.Errorf("expected error throwing to a tag without catch")
+	}
+}
+
+// TestBuiltinBlock tests the builtinBlock and builtinReturnFrom functions
+func TestBuiltinBlock(t *testing.T) {
+	env := newTestEnvironment()
+	definitions := []string{
+		`(defun early (n) (if (< n 0) (return-from early "negative") (* n 2)))`,
+		`(defun escape () (block out (lambda () (return-from out 1))))`,
+	}
+	for _, definition := range definitions {
+		if _, err := Eval(env, parseExpr(t, definition)); err != nil {
+			t.Fatalf("Eval(%s) failed: %v", definition, err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected LispValue
+	}{
+		{`(block outer (+ 1 (return-from outer 10)) 20)`, &LispNumber{Value: 10}},
+		{`(block nil (return 5) 6)`, &LispNumber{Value: 5}},
+		{`(block outer (block inner (return-from outer 1)) 2)`, &LispNumber{Value: 1}},
+		{`(block outer (return-from outer))`, &LispNil{}},
+		{`(early -1)`, &LispString{Value: "negative"}},
+		{`(early 4)`, &LispNumber{Value: 8}},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	errorTests := []string{
+		`(return-from missing 1)`,
+		`(let ((f (escape))) (f))`,
+	}
+	for _, input := range errorTests {
+		if _, err := Eval(env, parseExpr(t, input)); err == nil {
+			t.Errorf("Eval(%s) expected error", input)
+		}
+	}
+}
+
+// TestBuiltinCallCC tests the builtinCallCC function
+func TestBuiltinCallCC(t *testing.T) {
+	env := newTestEnvironment()
+
+	tests := []struct {
+		input    string
+		expected LispValue
+	}{
+		{`(call/cc (lambda (k) (+ 1 (k 42))))`, &LispNumber{Value: 42}},
+		{`(+ 1 (call/cc (lambda (k) 1)))`, &LispNumber{Value: 2}},
+		{`(call-with-current-continuation (lambda (return) (+ 1 (return 5) (error "not reached"))))`, &LispNumber{Value: 5}},
+		{`(call/cc (lambda (outer) (+ 2 (call/cc (lambda (inner) (outer 1))))))`, &LispNumber{Value: 1}},
+		{`(car (call/cc (lambda (car) (car '(7 8)))))`, &LispNumber{Value: 7}},
+		{`(call/cc (lambda (if) (if (< 1 2) 2 3)))`, &LispNumber{Value: 2}},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	if _, err := Eval(env, parseExpr(t, `(let ((k (call/cc (lambda (k) k)))) (k 1))`)); err == nil {
+		t.Errorf("expected error re-entering a continuation")
+	}
+}
+
+// TestBuiltinDefvar tests the builtinDefvar function
+func TestBuiltinDefvar(t *testing.T) {
+	env := newTestEnvironment()
+	definitions := []string{
+		`(defvar *depth* 1)`,
+		`(defun current-depth () *depth*)`,
+		`(defun depth-in (*depth*) (current-depth))`,
+	}
+	for _, definition := range definitions {
+		if _, err := Eval(env, parseExpr(t, definition)); err != nil {
+			t.Fatalf("Eval(%s) failed: %v", definition, err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected LispValue
+	}{
+		{`(current-depth)`, &LispNumber{Value: 1}},
+		{`(let ((*depth* 2)) (current-depth))`, &LispNumber{Value: 2}},
+		{`(current-depth)`, &LispNumber{Value: 1}},
+		{`(handler-case (let ((*depth* 3)) (error "boom")) (error (c) (current-depth)))`, &LispNumber{Value: 1}},
+		{`(catch 'seen (handler-bind ((error (lambda (c) (throw 'seen (current-depth))))) (let ((*depth* 4)) (error "boom"))))`, &LispNumber{Value: 4}},
+		{`(depth-in 5)`, &LispNumber{Value: 5}},
+		{`(let ((f (lambda (*depth*) (current-depth)))) (f 6))`, &LispNumber{Value: 6}},
+		{`(multiple-value-bind (*depth* other) (values 7 8) (current-depth))`, &LispNumber{Value: 7}},
+		{`(receive (*depth*) (values 8) (current-depth))`, &LispNumber{Value: 8}},
+		{`(handler-case (depth-in (error "boom")) (error (c) (current-depth)))`, &LispNumber{Value: 1}},
+		{`(handler-case (multiple-value-bind (*depth*) 2 (error "boom")) (error (c) (current-depth)))`, &LispNumber{Value: 1}},
+		{`(current-depth)`, &LispNumber{Value: 1}},
+		{`(defvar *depth* 9 "ignored as already bound")`, &LispAtom{Value: "*depth*", Line: 1, Column: 9}},
+		{`(current-depth)`, &LispNumber{Value: 1}},
+		{`(defparameter *depth* 9)`, &LispAtom{Value: "*depth*", Line: 1, Column: 15}},
+		{`(current-depth)`, &LispNumber{Value: 9}},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	if _, err := Eval(env, parseExpr(t, `(defvar *unbound-special*)`)); err != nil {
+		t.Fatalf("defvar without value failed: %v", err)
+	}
+	if _, err := Eval(env, &LispAtom{Value: "*unbound-special*"}); err == nil {
+		t.Errorf("expected error reading an unbound special variable")
+	}
+	_, err := Eval(env, parseExpr(t, "\n  *unbound-special*"))
+	if c := conditionFromError(err); c.Line != 2 || c.Column != 3 {
+		t.Errorf("expected unbound special variable at line 2, column 3, got %v", err)
+	}
+}
+
+// TestBuiltinParameterize tests the builtinMakeParameter and builtinParameterize functions
+func TestBuiltinParameterize(t *testing.T) {
+	env := newTestEnvironment()
+	definitions := []string{
+		`(defparameter *radix* (make-parameter 10))`,
+		`(defvar *double* (lambda (x) (* 2 x)))`,
+		`(defvar *use-five* (lambda (c) (invoke-restart 'use-value 5)))`,
+	}
+	for _, definition := range definitions {
+		if _, err := Eval(env, parseExpr(t, definition)); err != nil {
+			t.Fatalf("Eval(%s) failed: %v", definition, err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected LispValue
+	}{
+		{`(let ((p (make-parameter 10))) (p))`, &LispNumber{Value: 10}},
+		{`(let ((radix (make-parameter 10))) (let ((show (lambda () (radix)))) (parameterize ((radix 2)) (show))))`, &LispNumber{Value: 2}},
+		{`(let ((p (make-parameter 1 (lambda (x) (* x 10))))) (parameterize ((p 2)) (p)))`, &LispNumber{Value: 20}},
+		{`(let ((p (make-parameter 1))) (+ (parameterize ((p 2)) (p)) (p)))`, &LispNumber{Value: 3}},
+		{`(let ((p (make-parameter 1))) (+ (catch 'out (parameterize ((p 2)) (throw 'out (p)))) (p)))`, &LispNumber{Value: 3}},
+		{`(*radix*)`, &LispNumber{Value: 10}},
+		{`(parameterize ((*radix* 16)) (*radix*))`, &LispNumber{Value: 16}},
+		{`(*double* 4)`, &LispNumber{Value: 8}},
+		{`(let ((*double* (lambda (x) (* 3 x)))) (*double* 4))`, &LispNumber{Value: 12}},
+		{`(restart-case (handler-bind ((error '*use-five*)) (car 5)) (use-value (v) v))`, &LispNumber{Value: 5}},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	if _, err := Eval(env, parseExpr(t, `(parameterize ((1 2)) 3)`)); err == nil {
+		t.Errorf("expected error parameterizing a non-parameter")
+	}
+}
+
+// TestMultipleValues tests values and the forms receiving multiple values
+func TestMultipleValues(t *testing.T) {
+	env := newTestEnvironment()
+	if _, err := Eval(env, parseExpr(t, `(defun two () (values 1 2))`)); err != nil {
+		t.Fatalf("defun failed: %v", err)
+	}
+	list := func(nums ...int) LispValue {
+		elements := []LispValue{}
+		for _, n := range nums {
+			elements = append(elements, &LispNumber{Value: n})
+		}
+		return &LispList{Elements: elements}
+	}
+
+	tests := []struct {
+		input    string
+		expected LispValue
+	}{
+		{`(values 1 2)`, &LispNumber{Value: 1}},
+		{`(values)`, &LispNil{}},
+		{`(+ (values 1 2) 10)`, &LispNumber{Value: 11}},
+		{`(multiple-value-list (values 1 2 3))`, list(1, 2, 3)},
+		{`(multiple-value-list (values))`, list()},
+		{`(multiple-value-list (two))`, list(1, 2)},
+		{`(multiple-value-list (if true (values 1 2) 3))`, list(1, 2)},
+		{`(multiple-value-list (floor/ -7 2))`, list(-4, 1)},
+		{`(multiple-value-list (truncate/ -7 2))`, list(-3, -1)},
+		{`(multiple-value-list (call/cc (lambda (k) (k 1 2))))`, list(1, 2)},
+		{`(multiple-value-bind (q r) (floor/ 7 2) (+ (* q 10) r))`, &LispNumber{Value: 31}},
+		{`(multiple-value-bind (a b c) (values 1 2) c)`, &LispNil{}},
+		{`(call-with-values (lambda () (values 1 2)) (lambda (a b) (+ a b)))`, &LispNumber{Value: 3}},
+		{`(receive (a b) (values 1 2) (- a b))`, &LispNumber{Value: -1}},
+		{`(receive all (values 1 2) all)`, list(1, 2)},
+		{`(receive (a . rest) (values 1 2 3) rest)`, list(2, 3)},
+		{`(receive (a b . rest) (values 1 2) (cons b rest))`, list(2)},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || !lispValueEqual(result, test.expected) {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	for _, input := range []string{`(receive (a b) (values 1 2 3) a)`, `(receive (a b . rest) (values 1) a)`} {
+		if _, err := Eval(env, parseExpr(t, input)); err == nil {
+			t.Errorf("Eval(%s) expected error", input)
+		}
+	}
+
+	result, err := evalValues(env, parseExpr(t, `(two)`))
+	if err != nil || result.String() != "1\n2" {
+		t.Errorf("evalValues((two)) = %q, %v, want every value", result, err)
+	}
+}
+
+// TestBuiltinDefstruct tests the builtinDefstruct and builtinDefineRecordType functions
+func TestBuiltinDefstruct(t *testing.T) {
+	env := newTestEnvironment()
+	definitions := []string{
+		`(defstruct point x (y 0))`,
+		`(defstruct (account (:conc-name acct-) (:constructor new-account) (:copier nil)) owner (balance (* 10 10)))`,
+		`(define-record-type <kons> (kons a d) kons? (a kar set-kar!) (d kdr))`,
+	}
+	for _, definition := range definitions {
+		if _, err := Eval(env, parseExpr(t, definition)); err != nil {
+			t.Fatalf("Eval(%s) failed: %v", definition, err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`(make-point :x 1)`, "#S(point :x 1 :y 0)"},
+		{`(point-x (make-point :x 5 :y 6))`, "5"},
+		{`(point-p (make-point))`, "true"},
+		{`(point-p 5)`, "false"},
+		{`(let ((p (make-point :x 1))) (equal p (copy-point p)))`, "true"},
+		{`(equal (make-point :x 1) (make-point :x 2))`, "false"},
+		{`(acct-balance (new-account :owner "ann"))`, "100"},
+		{`(kons 1 "x")`, `#S(kons :a 1 :d "x")`},
+		{`(kdr (kons 1 2))`, "2"},
+		{`(let ((p (kons 1 2))) (let ((ignored (set-kar! p 3))) (kar p)))`, "3"},
+		{`(kons? (make-point))`, "false"},
+		{`(equal '(1 (2 "a")) '(1 (2 "a")))`, "true"},
+		{`(equal 1 1.0)`, "false"},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || result.String() != test.expected {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	errorTests := []string{
+		`(point-x 5)`,
+		`(make-point :z 1)`,
+		`(copy-account (new-account))`,
+		`(kons 1)`,
+	}
+	for _, input := range errorTests {
+		if _, err := Eval(env, parseExpr(t, input)); err == nil {
+			t.Errorf("Eval(%s) expected error", input)
+		}
+	}
+}
+
+// TestObjectSystem tests classes, instances and generic functions
+func TestObjectSystem(t *testing.T) {
+	env := newTestEnvironment()
+	definitions := []string{
+		`(defclass shape () ((name :initarg :name :initform "shape" :reader shape-name)))`,
+		`(defclass circle (shape) ((radius :initarg :radius :accessor radius :writer set-radius)))`,
+		`(defgeneric area (s))`,
+		`(defmethod area ((s circle)) (* 3 (radius s) (radius s)))`,
+		`(defmethod describe-it ((x t)) "thing")`,
+		`(defmethod describe-it ((x number)) (concat "number " (call-next-method)))`,
+		`(defmethod describe-it ((x integer)) (concat "integer " (call-next-method)))`,
+		`(defmethod describe-it ((x string)) (if (next-method-p) "string with next" "string"))`,
+		`(defmethod collide ((a shape) (b shape)) "shape-shape")`,
+		`(defmethod collide ((a circle) (b shape)) "circle-shape")`,
+		`(defmethod collide ((a shape) (b circle)) "shape-circle")`,
+		`(defclass logbook () ((entries :initform '() :accessor entries)))`,
+		`(defvar *log* (make-instance 'logbook))`,
+		`(defun note (x) (set-slot-value *log* 'entries (append (entries *log*) (cons x '()))))`,
+		`(defmethod greet ((s shape)) (note 'primary))`,
+		`(defmethod greet :before ((s shape)) (note 'before-shape))`,
+		`(defmethod greet :before ((s circle)) (note 'before-circle))`,
+		`(defmethod greet :after ((s shape)) (note 'after-shape))`,
+		`(defmethod greet :after ((s circle)) (note 'after-circle))`,
+		`(defmethod greet :around ((s circle)) (let ((ignored (note 'around))) (call-next-method)))`,
+		`(defvar *scale* 1)`,
+		`(defun scaled (x) (* *scale* x))`,
+		`(defmethod scale-by ((*scale* integer) (x integer)) (scaled x))`,
+	}
+	for _, definition := range definitions {
+		if _, err := Eval(env, parseExpr(t, definition)); err != nil {
+			t.Fatalf("Eval(%s) failed: %v", definition, err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`(shape-name (make-instance 'circle :radius 2))`, `"shape"`},
+		{`(shape-name (make-instance 'circle :name "ring"))`, `"ring"`},
+		{`(slot-value (make-instance 'circle :radius 2) 'radius)`, "2"},
+		{`(area (make-instance 'circle :radius 2))`, "12"},
+		{`(let ((c (make-instance 'circle :radius 1))) (let ((ignored (set-radius 5 c))) (radius c)))`, "5"},
+		{`(describe-it 1)`, `"integer number thing"`},
+		{`(describe-it 1.5)`, `"number thing"`},
+		{`(describe-it (pow 10 30))`, `"integer number thing"`},
+		{`(describe-it 1/3)`, `"number thing"`},
+		{`(class-of (pow 10 30))`, "#<class integer>"},
+		{`(describe-it "s")`, `"string with next"`},
+		{`(describe-it 'sym)`, `"thing"`},
+		{`(collide (make-instance 'circle) (make-instance 'shape))`, `"circle-shape"`},
+		{`(collide (make-instance 'shape) (make-instance 'circle))`, `"shape-circle"`},
+		{`(collide (make-instance 'circle) (make-instance 'circle))`, `"circle-shape"`},
+		{`(class-of (make-instance 'circle))`, "#<class circle>"},
+		{`(class-of '(1 2))`, "#<class list>"},
+		{`(find-class 'shape)`, "#<class shape>"},
+		{`(make-instance 'shape)`, "#<shape>"},
+		{`(scale-by 3 4)`, "12"},
+		{`(+ (scale-by 3 4) *scale*)`, "13"},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || result.String() != test.expected {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	if _, err := Eval(env, parseExpr(t, `(greet (make-instance 'circle))`)); err != nil {
+		t.Fatalf("greet failed: %v", err)
+	}
+	entries, err := Eval(env, parseExpr(t, `(entries *log*)`))
+	expected := "(around before-circle before-shape primary after-shape after-circle)"
+	if err != nil || entries.String() != expected {
+		t.Errorf("method combination order = %v, %v, want %v", entries, err, expected)
+	}
+
+	errorTests := []string{
+		`(area (make-instance 'shape))`,
+		`(slot-value (make-instance 'circle) 'radius)`,
+		`(slot-value (make-instance 'circle) 'missing)`,
+		`(make-instance 'circle :colour "red")`,
+		`(make-instance 'undefined-class)`,
+		`(area 1 2)`,
+	}
+	for _, input := range errorTests {
+		if _, err := Eval(env, parseExpr(t, input)); err == nil {
+			t.Errorf("Eval(%s) expected error", input)
+		}
+	}
+}
+
+func TestBuiltinMatch(t *testing.T) {
+	env := newTestEnvironment()
+	definitions := []string{
+		`(defstruct point x y)`,
+		`(defun describe (v) (match v (0 "zero") (:key "keyword") ('sym "symbol") ((? isString) "string") ((struct point 0 y) y) ((a b) (+ a b)) ((first . rest) rest) (_ "other")))`,
+	}
+	for _, definition := range definitions {
+		if _, err := Eval(env, parseExpr(t, definition)); err != nil {
+			t.Fatalf("Eval(%s) failed: %v", definition, err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`(describe 0)`, `"zero"`},
+		{`(describe :key)`, `"keyword"`},
+		{`(describe 'sym)`, `"symbol"`},
+		{`(describe "s")`, `"string"`},
+		{`(describe (make-point :x 0 :y 7))`, "7"},
+		{`(describe '(1 2))`, "3"},
+		{`(describe '(1 2 3))`, "(2 3)"},
+		{`(describe 5)`, `"other"`},
+		{`(match 4 ((? (lambda (n) (> n 3)) n) (* n 2)))`, "8"},
+		{`(match '(1 (2 3)) ((a (b c)) (list a b c)) (_ 0))`, "(a b c)"},
+		{`(match '() (() "empty"))`, `"empty"`},
+		{`(match '(1) ((_ . rest) rest))`, "()"},
+		{`(handler-case (match 5 (1 "one")) (match-error (c) (condition-irritants c)))`, "(5)"},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(env, parseExpr(t, test.input))
+		if err != nil || result.String() != test.expected {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	errorTests := []string{
+		`(match 5 (1 "one"))`,
+		`(match '(1 2) ((a) a))`,
+		`(match (make-point) ((struct point x) x))`,
+	}
+	for _, input := range errorTests {
+		if _, err := Eval(env, parseExpr(t, input)); err == nil {
+			t.Errorf("Eval(%s) expected error", input)
+		}
+	}
+}
+
+func TestBuiltinDestructuringBind(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`(destructuring-bind (a b) '(1 2) (+ a b))`, "3"},
+		{`(destructuring-bind (a (b c)) '(1 (2 3)) (* a b c))`, "6"},
+		{`(destructuring-bind (a . rest) '(1 2 3) rest)`, "(2 3)"},
+		{`(destructuring-bind (a &optional (b 10) c) '(1) (if c 0 b))`, "10"},
+		{`(destructuring-bind (a &rest rest) '(1 2 3) rest)`, "(2 3)"},
+		{`(destructuring-bind (a &key (b 2) c) '(1 :c 3) (+ a b c))`, "6"},
+		{`(destructuring-bind (&rest all &key x) '(:x 1) all)`, "(:x 1)"},
+	}
+
+	for _, test := range tests {
+		result, err := Eval(newTestEnvironment(), parseExpr(t, test.input))
+		if err != nil || result.String() != test.expected {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	errorTests := []string{
+		`(destructuring-bind (a b) '(1) a)`,
+		`(destructuring-bind (a) '(1 2) a)`,
+		`(destructuring-bind (a (b)) '(1 2) a)`,
+		`(destructuring-bind (&key a) '(:b 1) a)`,
+	}
+	for _, input := range errorTests {
+		if _, err := Eval(newTestEnvironment(), parseExpr(t, input)); err == nil {
+			t.Errorf("Eval(%s) expected error", input)
+		}
+	}
+}
+
+func TestInterpreter(t *testing.T) {
+	ctx := context.Background()
+	first, second := NewInterpreter(), NewInterpreter()
+	first.Define("x", &LispNumber{Value: 2})
+
+	result, err := first.EvalString(ctx, `(defvar *depth* 1) (defclass point () (x)) (defun twice (n) (* n x)) (twice 21)`)
+	if err != nil || result.String() != "42" {
+		t.Fatalf("EvalString = %v, %v, want 42", result, err)
+	}
+	if val, ok := first.Lookup("*depth*"); !ok || val.String() != "1" {
+		t.Errorf("Lookup(*depth*) = %v, %v, want 1", val, ok)
+	}
+
+	for _, name := range []string{"x", "twice", "*depth*"} {
+		if val, ok := second.Lookup(name); ok {
+			t.Errorf("second interpreter sees %s = %v", name, val)
+		}
+	}
+	if _, err := second.EvalString(ctx, `(make-instance 'point)`); err == nil {
+		t.Errorf("second interpreter sees the class of the first")
+	}
+
+	first.Seed(7)
+	second.Seed(7)
+	a, errA := first.EvalString(ctx, `(random 1000000)`)
+	b, errB := second.EvalString(ctx, `(random 1000000)`)
+	if errA != nil || errB != nil || a.String() != b.String() {
+		t.Errorf("random with the same seed = %v, %v and %v, %v", a, errA, b, errB)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := first.EvalString(cancelled, `(twice 1)`); err == nil {
+		t.Errorf("EvalString with a cancelled context expected error")
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	interp := NewInterpreter()
+	interp.RegisterFunc("square", func(args []LispValue) (LispValue, error) {
+		n, ok := args[0].(*LispNumber)
+		if !ok {
+			return nil, fmt.Errorf("invalid argument to square: %v", args[0])
+		}
+		return &LispNumber{Value: n.Value * n.Value}, nil
+	}, "squares a number", 1)
+	interp.RegisterFunc("count-args", func(args []LispValue) (LispValue, error) {
+		return &LispNumber{Value: len(args)}, nil
+	}, "number of arguments", -1)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`(square (+ 1 2))`, "9"},
+		{`(count-args 1 "a" 'b)`, "3"},
+		{`(count-args)`, "0"},
+		{`(help 'square)`, `"squares a number"`},
+		{`(help 'car)`, `"car list operation. It retrieves first element of a list."`},
+		{`(let ((f (lambda (x) (square x)))) (f 5))`, "25"},
+	}
+	for _, test := range tests {
+		result, err := Eval(interp.env, parseExpr(t, test.input))
+		if err != nil || result.String() != test.expected {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	errorTests := []string{
+		`(square 1 2)`,
+		`(square "a")`,
+		`(help 'undefined-builtin)`,
+	}
+	for _, input := range errorTests {
+		if _, err := Eval(interp.env, parseExpr(t, input)); err == nil {
+			t.Errorf("Eval(%s) expected error", input)
+		}
+	}
+
+	found := false
+	for _, builtin := range interp.Builtins() {
+		found = found || builtin.Name == "square" && builtin.Arity == 1
+	}
+	if !found {
+		t.Errorf("Builtins() doesn't list square")
+	}
+	if _, err := Eval(NewInterpreter().env, parseExpr(t, `(square 2)`)); err == nil {
+		t.Errorf("another interpreter sees square")
+	}
+	interp.Unregister("square")
+	if _, err := Eval(interp.env, parseExpr(t, `(square 2)`)); err == nil {
+		t.Errorf("square is still registered")
+	}
+}
+
+type marshalPoint struct {
+	X      int     `lisp:"x"`
+	Y      float64 `lisp:"y"`
+	Label  string
+	hidden bool
+	Skip   string `lisp:"-"`
+}
+
+func TestMarshal(t *testing.T) {
+	when := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		input    any
+		expected string
+	}{
+		{nil, "nil"},
+		{int8(-3), "-3"},
+		{uint64(1 << 63), "9223372036854775808"},
+		{float32(1.5), "1.5"},
+		{"text", `"text"`},
+		{true, "true"},
+		{[]int{1, 2, 3}, "(1 2 3)"},
+		{[2]string{"a", "b"}, `("a" "b")`},
+		{map[string]int{"b": 2, "a": 1}, `(("a" 1) ("b" 2))`},
+		{marshalPoint{X: 1, Y: 2.5, Label: "p"}, `(:x 1 :y 2.5 :label "p")`},
+		{&marshalPoint{}, `(:x 0 :y 0.0 :label "")`},
+		{(*marshalPoint)(nil), "nil"},
+		{when, `"2024-03-01T12:30:00Z"`},
+		{big.NewRat(1, 3), "1/3"},
+		{&LispAtom{Value: "sym"}, "sym"},
+	}
+	for _, test := range tests {
+		result, err := ToLisp(test.input)
+		if err != nil || result.String() != test.expected {
+			t.Errorf("ToLisp(%#v) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+	if _, err := ToLisp(make(chan int)); err == nil {
+		t.Errorf("ToLisp(chan) expected error")
+	}
+
+	var point marshalPoint
+	if err := FromLisp(parseExpr(t, `(:x 4 :y 1/2 :label "q")`), &point); err != nil || point != (marshalPoint{X: 4, Y: 0.5, Label: "q"}) {
+		t.Errorf("FromLisp into struct = %+v, %v", point, err)
+	}
+	var ints []int16
+	if err := FromLisp(parseExpr(t, `(1 2 3)`), &ints); err != nil || !reflect.DeepEqual(ints, []int16{1, 2, 3}) {
+		t.Errorf("FromLisp into []int16 = %v, %v", ints, err)
+	}
+	var counts map[string]int
+	if err := FromLisp(parseExpr(t, `(("a" 1) ("b" 2))`), &counts); err != nil || !reflect.DeepEqual(counts, map[string]int{"a": 1, "b": 2}) {
+		t.Errorf("FromLisp into map = %v, %v", counts, err)
+	}
+	var parsed time.Time
+	if err := FromLisp(&LispString{Value: "2024-03-01T12:30:00Z"}, &parsed); err != nil || !parsed.Equal(when) {
+		t.Errorf("FromLisp into time.Time = %v, %v", parsed, err)
+	}
+	var anything any
+	if err := FromLisp(parseExpr(t, `(1 "a" (2.5))`), &anything); err != nil || !reflect.DeepEqual(anything, []any{1, "a", []any{2.5}}) {
+		t.Errorf("FromLisp into any = %#v, %v", anything, err)
+	}
+	var ptr *int
+	if err := FromLisp(&LispNumber{Value: 7}, &ptr); err != nil || ptr == nil || *ptr != 7 {
+		t.Errorf("FromLisp into *int = %v, %v", ptr, err)
+	}
+
+	var small int8
+	var flag bool
+	errorTests := []struct {
+		input  LispValue
+		target any
+	}{
+		{&LispNumber{Value: 300}, &small},
+		{&LispFloat{Value: 1.5}, &small},
+		{&LispString{Value: "x"}, &flag},
+		{&LispNumber{Value: 1}, small},
+	}
+	for _, test := range errorTests {
+		if err := FromLisp(test.input, test.target); err == nil {
+			t.Errorf("FromLisp(%v, %T) expected error", test.input, test.target)
+		}
+	}
+}
+
+func TestRegisterGoFunc(t *testing.T) {
+	interp := NewInterpreter()
+	funcs := map[string]any{
+		"scale": func(p marshalPoint, factor int) marshalPoint {
+			return marshalPoint{X: p.X * factor, Y: p.Y * float64(factor), Label: p.Label}
+		},
+		"parse-int": func(s string) (int, error) {
+			var n int
+			_, err := fmt.Sscan(s, &n)
+			return n, err
+		},
+		"join": func(sep string, parts ...string) string {
+			return strings.Join(parts, sep)
+		},
+		"split":   func(n int) (int, int) { return n / 2, n % 2 },
+		"nothing": func() {},
+		"neg":     func(b bool) bool { return !b },
+	}
+	for name, fn := range funcs {
+		if err := interp.RegisterGoFunc(name, fn, ""); err != nil {
+			t.Fatalf("RegisterGoFunc(%s) failed: %v", name, err)
+		}
+	}
+	if err := interp.RegisterGoFunc("bad", 42, ""); err == nil {
+		t.Errorf("RegisterGoFunc(42) expected error")
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`(scale '(:x 1 :y 1.5 :label "p") 2)`, `(:x 2 :y 3.0 :label "p")`},
+		{`(parse-int "42")`, "42"},
+		{`(join "-" "a" "b" "c")`, `"a-b-c"`},
+		{`(join ",")`, `""`},
+		{`(multiple-value-list (split 7))`, "(3 1)"},
+		{`(nothing)`, "nil"},
+		{`(neg (< 1 2))`, "false"},
+		{`(neg (= 1 2))`, "true"},
+		{`(neg nil)`, "true"},
+	}
+	for _, test := range tests {
+		result, err := Eval(interp.env, parseExpr(t, test.input))
+		if err != nil || result.String() != test.expected {
+			t.Errorf("Eval(%s) = %v, %v, want %v", test.input, result, err, test.expected)
+		}
+	}
+
+	errorTests := []string{
+		`(parse-int "x")`,
+		`(parse-int 1)`,
+		`(scale 1)`,
+		`(join)`,
+		`(neg 'maybe)`,
+	}
+	for _, input := range errorTests {
+		if _, err := Eval(interp.env, parseExpr(t, input)); err == nil {
+			t.Errorf("Eval(%s) expected error", input)
+		}
+	}
+}
+
+// errMissing is a sentinel error returned by a Go function
+var errMissing = errors.New("missing")
+
+// lookupError is an error type returned by a Go function
+type lookupError struct {
+	Key string
+}
+
+func (e *lookupError) Error() string {
+	return "no value for " + e.Key
+}
+
+// TestGoFuncErrors tests that the errors of Go functions can be told apart
+// once returned by an evaluation
+func TestGoFuncErrors(t *testing.T) {
+	interp := NewInterpreter()
+	interp.RegisterGoFunc("fetch", func(key string) (string, error) {
+		if key == "" {
+			return "", errMissing
+		}
+		return "", &lookupError{Key: key}
+	}, "")
+
+	_, err := interp.EvalForms(context.Background(), "(+ 1 2)\n(fetch \"\")")
+	var lispErr *LispError
+	if !errors.Is(err, errMissing) || !errors.As(err, &lispErr) || lispErr.Line != 2 {
+		t.Errorf("EvalForms(fetch \"\") = %v, want errMissing at line 2", err)
+	}
+
+	_, err = interp.EvalForms(context.Background(), `(handler-bind ((error (lambda (c) 0))) (fetch "port"))`)
+	var lookupErr *lookupError
+	if !errors.As(err, &lookupErr) || lookupErr.Key != "port" {
+		t.Errorf("EvalForms(fetch \"port\") = %v, want a lookupError", err)
+	}
+}
+
+func TestEvalCancellation(t *testing.T) {
+	interp := NewInterpreter()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := interp.EvalString(ctx, `(defun spin (n) (spin (+ n 1))) (ignore-errors (handler-case (spin 0) (error (c) 0)))`)
+	var cancelled *CancelledError
+	if !errors.As(err, &cancelled) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("EvalString of an endless recursion = %v, want a deadline CancelledError", err)
+	}
+
+	result, err := interp.EvalString(context.Background(), `(+ 1 2)`)
+	if err != nil || result.String() != "3" {
+		t.Errorf("EvalString after a cancellation = %v, %v, want 3", result, err)
+	}
+}
+
+func TestSandbox(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits Limits
+		src    string
+		limit  string
+	}{
+		{"steps", Limits{MaxSteps: 1000}, `(defun spin (n) (spin (+ n 1))) (spin 0)`, LIMIT_STEPS},
+		{"depth", Limits{MaxDepth: 50}, `(defun down (n) (if (= n 0) 0 (+ 1 (down (- n 1))))) (down 100)`, LIMIT_DEPTH},
+		{"allocation", Limits{MaxAllocBytes: 1000}, `(defun grow (s) (grow (concat s s))) (grow "ab")`, LIMIT_ALLOC},
+		{"unsafe builtin", Limits{}, `(read)`, LIMIT_BUILTINS},
+		{"whitelist", Limits{Builtins: []string{PLUS}}, `(+ 1 (* 2 3))`, LIMIT_BUILTINS},
+		{"handlers", Limits{MaxSteps: 100}, `(defun spin (n) (spin (+ n 1))) (ignore-errors (spin 0))`, LIMIT_STEPS},
+		{"rebinding the interpreter", Limits{}, `(let ((%interpreter 0)) (read-line))`, LIMIT_BUILTINS},
+		{"builtin handler", Limits{}, `(handler-bind ((error 'read-line)) (car 5))`, LIMIT_BUILTINS},
+		{"big shift", Limits{MaxAllocBytes: 1 << 20}, `(ash 1 2000000000)`, LIMIT_ALLOC},
+		{"big power", Limits{MaxAllocBytes: 1 << 20}, `(pow 10 100000000)`, LIMIT_ALLOC},
+		{"big numbers returned", Limits{MaxAllocBytes: 1000}, `(defun grow (n) (grow (pow n 2))) (grow 12345678901)`, LIMIT_ALLOC},
+		{"big square root", Limits{MaxSteps: 1000}, `(isqrt (ash 1 100000000))`, LIMIT_STEPS},
+		{"steps of several forms", Limits{MaxSteps: 300}, `(defun down (n) (if (= n 0) 0 (down (- n 1)))) (down 40) (down 40) (down 40)`, LIMIT_STEPS},
+		{"allocation of several forms", Limits{MaxAllocBytes: 100}, `(defparameter *a* (concat "aaaaaaaaaaaaaaaaaaaa" "aaaaaaaaaaaaaaaaaaaa")) (defparameter *b* (concat *a* "")) (defparameter *c* (concat *a* ""))`, LIMIT_ALLOC},
+		{"steps after rebinding", Limits{MaxSteps: 100}, `(defun spin (n) (spin (+ n 1))) (let ((%interpreter 0)) (spin 0))`, LIMIT_STEPS},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interp := NewInterpreter()
+			interp.Sandbox(tt.limits)
+			_, err := interp.EvalString(context.Background(), tt.src)
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) || limitErr.Limit != tt.limit {
+				t.Errorf("EvalString(%q) = %v, want a %s LimitError", tt.src, err, tt.limit)
+			}
+		})
+	}
+
+	interp := NewInterpreter()
+	interp.Sandbox(Limits{MaxSteps: 100, MaxDepth: 20, MaxAllocBytes: 1000})
+	for i := 0; i < 3; i++ {
+		result, err := interp.EvalString(context.Background(), `(defun fact (n) (if (= n 0) 1 (* n (fact (- n 1))))) (fact 5)`)
+		if err != nil || result.String() != "120" {
+			t.Fatalf("EvalString of a program within the limits = %v, %v, want 120", result, err)
+		}
+	}
+	for _, builtin := range interp.Builtins() {
+		if builtin.Name == READ {
+			t.Errorf("Builtins() of a sandboxed interpreter lists %s", READ)
+		}
+	}
+
+	interp = NewInterpreter()
+	interp.RegisterGoFunc("read-file", os.ReadFile, "reads a file")
+	interp.RegisterGoFunc("upcase", strings.ToUpper, "upper case of a string")
+	if err := interp.MarkUnsafe("read-file"); err != nil {
+		t.Fatalf("MarkUnsafe(read-file) failed: %v", err)
+	}
+	if err := interp.MarkUnsafe("no-such-builtin"); err == nil {
+		t.Errorf("MarkUnsafe of an unknown builtin expected error")
+	}
+	interp.Sandbox(Limits{})
+	var limitErr *LimitError
+	if _, err := interp.EvalString(context.Background(), `(read-file "/etc/passwd")`); !errors.As(err, &limitErr) {
+		t.Errorf("calling a host builtin marked unsafe = %v, want a LimitError", err)
+	}
+	if result, err := interp.EvalString(context.Background(), `(upcase "ok")`); err != nil || result.String() != `"OK"` {
+		t.Errorf("calling a safe host builtin = %v, %v, want \"OK\"", result, err)
+	}
+}
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"42", []string{"42"}, false},
+		{"(+ 1 2)", []string{"(+ 1 2)"}, false},
+		{"(defun f (x) x)\n(f 1)\n\"done\"", []string{"(defun f (x) x)", "(f 1)", `"done"`}, false},
+		{"'a b", []string{"(quote a)", "b"}, false},
+		{"(+ 1 2))", nil, true},
+		{"(+ 1 (* 2 3)", nil, true},
+	}
+	for _, tt := range tests {
+		forms, err := NewReader(strings.NewReader(tt.input)).ReadAll()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ReadAll(%q) expected error, got %v", tt.input, forms)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ReadAll(%q) unexpected error: %v", tt.input, err)
+			continue
+		}
+		got := make([]string, 0, len(forms))
+		for _, form := range forms {
+			got = append(got, form.String())
+		}
+		if len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("ReadAll(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	reader := NewReader(strings.NewReader("1 2"))
+	for _, want := range []string{"1", "2"} {
+		if form, err := reader.Next(); err != nil || form.String() != want {
+			t.Errorf("Next() = %v, %v, want %s", form, err, want)
+		}
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("Next() at the end = %v, want io.EOF", err)
+	}
+}
+
+func TestSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"(+ 1 2", []string{"1:1-1:2 unmatched (: the list is never closed"}},
+		{"(+ 1 2))", []string{"1:8-1:9 unexpected ): no list to close"}},
+		{`(print "hello)`, []string{"1:1-1:2 unmatched (: the list is never closed", "1:8-1:15 unterminated string"}},
+		{"(+ 1 #x1g)", []string{"1:6-1:10 malformed number: #x1g: invalid digits for radix 16"}},
+		{"(f ')", []string{"1:4-1:5 missing expression after '"}},
+		{"(a 1x)\n(b)\n)\n(c (d)", []string{
+			"1:4-1:6 malformed number: 1x: invalid digits for radix 10",
+			"3:1-3:2 unexpected ): no list to close",
+			"4:1-4:2 unmatched (: the list is never closed",
+		}},
+	}
+	for _, tt := range tests {
+		_, err := NewReader(strings.NewReader(tt.input)).ReadAll()
+		var errs SyntaxErrors
+		if !errors.As(err, &errs) {
+			t.Errorf("ReadAll(%q) = %v, want SyntaxErrors", tt.input, err)
+			continue
+		}
+		got := make([]string, len(errs))
+		for i, e := range errs {
+			got[i] = fmt.Sprintf("%d:%d-%d:%d %s", e.Line, e.Column, e.EndLine, e.EndColumn, e.Message)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ReadAll(%q) errors = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	reader := NewReader(strings.NewReader("(a 1x) (b 2)"))
+	if _, err := reader.Next(); err == nil {
+		t.Errorf("Next() of a malformed form expected error")
+	}
+	if form, err := reader.Next(); err != nil || form.String() != "(b 2)" {
+		t.Errorf("Next() after a malformed form = %v, %v, want (b 2)", form, err)
+	}
+}
+
+func TestStreamReader(t *testing.T) {
+	pr, pw := io.Pipe()
+	reader := NewReader(pr)
+	go func() {
+		pw.Write([]byte("(+ 1\n 2) "))
+	}()
+	form, err := reader.Next()
+	if err != nil || form.String() != "(+ 1 2)" {
+		t.Fatalf("Next() before the rest of the input = %v, %v, want (+ 1 2)", form, err)
+	}
+
+	go func() {
+		pw.Write([]byte(`"a b" (f 'x)`))
+		pw.Close()
+	}()
+	for _, want := range []string{`"a b"`, "(f (quote x))"} {
+		if form, err := reader.Next(); err != nil || form.String() != want {
+			t.Errorf("Next() = %v, %v, want %s", form, err, want)
+		}
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("Next() at the end = %v, want io.EOF", err)
+	}
+
+	lexer := NewLexer(strings.NewReader("(f\n  12)"))
+	var tokens []Token
+	for {
+		token, err := lexer.Next()
+		if err != nil {
+			break
+		}
+		tokens = append(tokens, token)
+	}
+	if !reflect.DeepEqual(tokens, Tokenize("(f\n  12)")) || tokens[2].Line != 2 || tokens[2].Column != 3 {
+		t.Errorf("Lexer tokens = %v, want those of Tokenize", tokens)
+	}
+
+	interp := NewInterpreter()
+	result, err := interp.EvalReader(context.Background(), strings.NewReader("(defun twice (n) (* 2 n))\n(twice 21)"))
+	if err != nil || result.String() != "42" {
+		t.Errorf("EvalReader = %v, %v, want 42", result, err)
+	}
+}
+
+func TestParseCache(t *testing.T) {
+	cache := newParseCache(2)
+	for _, src := range []string{"(a 1)", "(b 2)", "(a 1)", "(c 3)"} {
+		forms, err := NewReader(strings.NewReader(src)).ReadAll()
+		if err != nil {
+			t.Fatalf("ReadAll(%q) failed: %v", src, err)
+		}
+		if _, ok := cache.get(src); !ok {
+			cache.put(src, forms)
+		}
+	}
+	if cache.len() != 2 {
+		t.Errorf("len() = %d, want 2", cache.len())
+	}
+	if _, ok := cache.get("(b 2)"); ok {
+		t.Errorf("get of the least recently used source text hit the cache")
+	}
+
+	forms, ok := cache.get("(a 1)")
+	if !ok {
+		t.Fatalf("get of a recently used source text missed the cache")
+	}
+	list := forms[0].(*LispList)
+	list.Elements[1] = &LispString{Value: "changed"}
+	list.Elements = append(list.Elements, &LispAtom{Value: "extra"})
+	if forms, _ := cache.get("(a 1)"); forms[0].String() != "(a 1)" {
+		t.Errorf("get after changing a cached form = %v, want (a 1)", forms[0])
+	}
+}
+
+// benchmarkSource is a program of a few hundred tokens read over and over
+const benchmarkSource = `(defun fact (n) (if (= n 0) 1 (* n (fact (- n 1)))))
+(defun fib (n) (if (< n 2) n (+ (fib (- n 1)) (fib (- n 2)))))
+(defun squares (l) (if (= (length l) 0) (list) (cons (* (car l) (car l)) (squares (cdr l)))))
+(let ((x 10) (y "some text")) (concat y (format "~a" (fact x))))
+(handler-case (error "failed ~a" 42) (error (c) (condition-message c)))
+(squares (list 1 2 3 4 5 6 7 8 9 10))`
+
+func BenchmarkRead(b *testing.B) {
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := NewReader(strings.NewReader(benchmarkSource)).ReadAll(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		interp := NewInterpreter()
+		for i := 0; i < b.N; i++ {
+			if _, err := interp.Read(benchmarkSource); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// Helper functions for tests
+
+func lispValueEqual(a, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// newTestEnvironment returns the global environment of a new interpreter
+func newTestEnvironment() Environment {
+	return NewInterpreter().env
+}
+
+// parseExpr tokenizes and parses a single expression
+func parseExpr(t *testing.T, input string) LispValue {
+	t.Helper()
+	expr, _, err := Parse(Tokenize(input))
+	if err != nil {
+		t.Fatalf("Parse(%q) failed: %v", input, err)
+	}
+	return expr
+}
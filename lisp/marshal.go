@@ -0,0 +1,476 @@
+package lisp
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MARSHAL_TAG is the struct field tag naming the keyword of a field, as in
+// `lisp:"name"`. The name "-" skips the field.
+const MARSHAL_TAG = "lisp"
+
+var (
+	lispValueType = reflect.TypeOf((*LispValue)(nil)).Elem()
+	errorType     = reflect.TypeOf((*error)(nil)).Elem()
+	timeType      = reflect.TypeOf(time.Time{})
+	bigIntType    = reflect.TypeOf((*big.Int)(nil))
+	bigRatType    = reflect.TypeOf((*big.Rat)(nil))
+)
+
+// ToLisp converts a Go value to a Lisp value. Numbers, strings and booleans
+// become the matching Lisp values, nil and nil pointers become nil, slices
+// and arrays become lists, maps become association lists ((key value)...)
+// sorted by key, structs become property lists (:field value...) and times
+// become RFC 3339 strings. Lisp values are returned as they are.
+func ToLisp(v any) (LispValue, error) {
+	if v == nil {
+		return &LispNil{}, nil
+	}
+	return toLisp(reflect.ValueOf(v))
+}
+
+// toLisp converts a reflected Go value to a Lisp value
+func toLisp(v reflect.Value) (LispValue, error) {
+	if isLispType(v.Type()) {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return &LispNil{}, nil
+		}
+		return v.Interface().(LispValue), nil
+	}
+	switch v.Type() {
+	case timeType:
+		return &LispString{Value: v.Interface().(time.Time).Format(time.RFC3339Nano)}, nil
+	case bigIntType:
+		if v.IsNil() {
+			return &LispNil{}, nil
+		}
+		return makeInteger(v.Interface().(*big.Int)), nil
+	case bigRatType:
+		if v.IsNil() {
+			return &LispNil{}, nil
+		}
+		return makeRational(new(big.Rat).Set(v.Interface().(*big.Rat))), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return &LispBoolean{Value: v.Bool()}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return makeInteger(big.NewInt(v.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return makeInteger(new(big.Int).SetUint64(v.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return &LispFloat{Value: v.Float()}, nil
+	case reflect.String:
+		return &LispString{Value: v.String()}, nil
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return &LispNil{}, nil
+		}
+		return toLisp(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return &LispString{Value: string(v.Bytes())}, nil
+		}
+		elements := make([]LispValue, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem, err := toLisp(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, elem)
+		}
+		return &LispList{Elements: elements}, nil
+	case reflect.Map:
+		pairs := make([]LispValue, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := toLisp(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			val, err := toLisp(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, &LispList{Elements: []LispValue{key, val}})
+		}
+		sort.Slice(pairs, func(i, j int) bool {
+			return pairs[i].(*LispList).Elements[0].String() < pairs[j].(*LispList).Elements[0].String()
+		})
+		return &LispList{Elements: pairs}, nil
+	case reflect.Struct:
+		var plist []LispValue
+		for _, field := range reflect.VisibleFields(v.Type()) {
+			name, ok := fieldKeyword(field)
+			if !ok {
+				continue
+			}
+			fieldVal, err := v.FieldByIndexErr(field.Index)
+			if err != nil {
+				// promoted through a nil embedded pointer
+				continue
+			}
+			val, err := toLisp(fieldVal)
+			if err != nil {
+				return nil, err
+			}
+			plist = append(plist, &LispAtom{Value: KEYWORD_PREFIX + name}, val)
+		}
+		return &LispList{Elements: plist}, nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to a Lisp value", v.Type())
+}
+
+// isLispType reports whether a type is one of the Lisp value types of this
+// package. Go types with a String method, such as time.Time, are not.
+func isLispType(t reflect.Type) bool {
+	if !t.Implements(lispValueType) {
+		return false
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.PkgPath() == lispValueType.PkgPath()
+}
+
+// fieldKeyword returns the keyword name of an exported struct field, from its
+// lisp tag or else its lower-cased name
+func fieldKeyword(field reflect.StructField) (string, bool) {
+	if !field.IsExported() || field.Anonymous {
+		return "", false
+	}
+	name, _, _ := strings.Cut(field.Tag.Get(MARSHAL_TAG), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return strings.ToLower(field.Name), true
+	}
+	return name, true
+}
+
+// FromLisp converts a Lisp value to a Go value and stores it in the value
+// target points to. It is the inverse of ToLisp, and also fills structs from
+// records and instances, taking each field from the slot of the same name.
+// A target of type any receives ints, float64s, *big.Rat, strings, bools,
+// []any or nil, and the Lisp value itself for other values.
+func FromLisp(val LispValue, target any) error {
+	ptr := reflect.ValueOf(target)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() {
+		return fmt.Errorf("cannot convert %v: target is not a pointer", val)
+	}
+	converted, err := fromLisp(val, ptr.Type().Elem())
+	if err != nil {
+		return err
+	}
+	ptr.Elem().Set(converted)
+	return nil
+}
+
+// fromLisp converts a Lisp value to a Go value of the given type
+func fromLisp(val LispValue, t reflect.Type) (reflect.Value, error) {
+	if val == nil {
+		val = &LispNil{}
+	}
+	fail := func() (reflect.Value, error) {
+		return reflect.Value{}, fmt.Errorf("cannot convert %v to %s", val, t)
+	}
+	if t.Kind() == reflect.Interface {
+		if t == lispValueType {
+			return reflect.ValueOf(&val).Elem(), nil
+		}
+		if t.NumMethod() > 0 {
+			if reflect.TypeOf(val).Implements(t) {
+				return reflect.ValueOf(val).Convert(t), nil
+			}
+			return fail()
+		}
+		result := reflect.New(t).Elem()
+		if goVal := toGo(val); goVal != nil {
+			result.Set(reflect.ValueOf(goVal))
+		}
+		return result, nil
+	}
+	if reflect.TypeOf(val).AssignableTo(t) {
+		return reflect.ValueOf(val), nil
+	}
+	_, isNil := val.(*LispNil)
+
+	switch t {
+	case timeType:
+		s, ok := val.(*LispString)
+		if !ok {
+			return fail()
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, s.Value)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("cannot convert %v to %s: %v", val, t, err)
+		}
+		return reflect.ValueOf(parsed), nil
+	case bigIntType:
+		if !isExact(val) || !toRat(val).IsInt() {
+			return fail()
+		}
+		return reflect.ValueOf(new(big.Int).Set(toRat(val).Num())), nil
+	case bigRatType:
+		if !isExact(val) {
+			return fail()
+		}
+		return reflect.ValueOf(toRat(val)), nil
+	}
+
+	result := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Bool:
+		switch v := val.(type) {
+		case *LispBoolean:
+			result.SetBool(v.Value)
+		case *LispAtom:
+			// comparisons return the true and false atoms
+			if v.Value != TRUE && v.Value != FALSE {
+				return fail()
+			}
+			result.SetBool(isTrue(v))
+		case *LispNil:
+		default:
+			return fail()
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !isExact(val) || !toRat(val).IsInt() || !toRat(val).Num().IsInt64() || result.OverflowInt(toRat(val).Num().Int64()) {
+			return fail()
+		}
+		result.SetInt(toRat(val).Num().Int64())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !isExact(val) || !toRat(val).IsInt() || !toRat(val).Num().IsUint64() || result.OverflowUint(toRat(val).Num().Uint64()) {
+			return fail()
+		}
+		result.SetUint(toRat(val).Num().Uint64())
+	case reflect.Float32, reflect.Float64:
+		if _, ok := numberRank(val); !ok {
+			return fail()
+		}
+		result.SetFloat(toFloat(val))
+	case reflect.String:
+		switch v := val.(type) {
+		case *LispString:
+			result.SetString(v.Value)
+		case *LispAtom:
+			result.SetString(v.Value)
+		default:
+			return fail()
+		}
+	case reflect.Pointer:
+		if isNil {
+			return result, nil
+		}
+		elem, err := fromLisp(val, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	case reflect.Slice:
+		if s, ok := val.(*LispString); ok && t.Elem().Kind() == reflect.Uint8 {
+			return reflect.ValueOf([]byte(s.Value)).Convert(t), nil
+		}
+		elements, ok := listElements(val)
+		if !ok {
+			return fail()
+		}
+		result = reflect.MakeSlice(t, len(elements), len(elements))
+		for i, elem := range elements {
+			converted, err := fromLisp(elem, t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			result.Index(i).Set(converted)
+		}
+	case reflect.Array:
+		elements, ok := listElements(val)
+		if !ok || len(elements) != t.Len() {
+			return fail()
+		}
+		for i, elem := range elements {
+			converted, err := fromLisp(elem, t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			result.Index(i).Set(converted)
+		}
+	case reflect.Map:
+		pairs, ok := listElements(val)
+		if !ok {
+			return fail()
+		}
+		result = reflect.MakeMapWithSize(t, len(pairs))
+		for _, pair := range pairs {
+			pairList, ok := pair.(*LispList)
+			if !ok || len(pairList.Elements) != 2 {
+				return fail()
+			}
+			key, err := fromLisp(pairList.Elements[0], t.Key())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			elem, err := fromLisp(pairList.Elements[1], t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			result.SetMapIndex(key, elem)
+		}
+	case reflect.Struct:
+		slots, ok := structSlots(val)
+		if !ok {
+			return fail()
+		}
+		for _, field := range reflect.VisibleFields(t) {
+			name, ok := fieldKeyword(field)
+			if !ok {
+				continue
+			}
+			slot, ok := slots[name]
+			if !ok {
+				continue
+			}
+			fieldVal, err := result.FieldByIndexErr(field.Index)
+			if err != nil {
+				// promoted through a nil embedded pointer
+				continue
+			}
+			converted, err := fromLisp(slot, field.Type)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			fieldVal.Set(converted)
+		}
+	default:
+		return fail()
+	}
+	return result, nil
+}
+
+// structSlots returns the slots of a value converted to a struct: the pairs
+// of a property list, or the slots of a record or an instance
+func structSlots(val LispValue) (map[string]LispValue, bool) {
+	slots := map[string]LispValue{}
+	switch v := val.(type) {
+	case *LispRecord:
+		for i, slot := range v.Type.Slots {
+			slots[slot] = v.Values[i]
+		}
+	case *LispInstance:
+		for slot, slotVal := range v.Slots {
+			slots[slot] = slotVal
+		}
+	default:
+		plist, ok := listElements(val)
+		if !ok || len(plist)%2 != 0 {
+			return nil, false
+		}
+		for i := 0; i < len(plist); i += 2 {
+			slots[strings.TrimPrefix(plist[i].String(), KEYWORD_PREFIX)] = plist[i+1]
+		}
+	}
+	return slots, true
+}
+
+// toGo converts a Lisp value to the natural Go value for it
+func toGo(val LispValue) any {
+	switch v := val.(type) {
+	case *LispNumber:
+		return v.Value
+	case *LispFloat:
+		return v.Value
+	case *LispRational:
+		return new(big.Rat).Set(v.Value)
+	case *LispString:
+		return v.Value
+	case *LispBoolean:
+		return v.Value
+	case *LispAtom:
+		if v.Value == TRUE || v.Value == FALSE {
+			return isTrue(v)
+		}
+	case *LispNil:
+		return nil
+	case *LispList:
+		elements := make([]any, 0, len(v.Elements))
+		for _, elem := range v.Elements {
+			elements = append(elements, toGo(elem))
+		}
+		return elements
+	}
+	return val
+}
+
+// RegisterGoFunc registers any Go function as a builtin. The arguments are
+// converted with FromLisp to the parameter types, and the results with
+// ToLisp: a function can return nothing, a value, an error, or a value
+// followed by an error. Several values are returned as multiple values.
+// Sandboxed interpreters let programs call it unless MarkUnsafe marks it.
+func (interp *Interpreter) RegisterGoFunc(name string, fn any, doc string) error {
+	fnVal := reflect.ValueOf(fn)
+	fnType := fnVal.Type()
+	if fnType.Kind() != reflect.Func {
+		return fmt.Errorf("cannot register %s: %s is not a function", name, fnType)
+	}
+	returnsError := fnType.NumOut() > 0 && fnType.Out(fnType.NumOut()-1) == errorType
+	arity := fnType.NumIn()
+	if fnType.IsVariadic() {
+		arity = -1
+	}
+
+	interp.RegisterFunc(name, func(args []LispValue) (LispValue, error) {
+		fixed := fnType.NumIn()
+		if fnType.IsVariadic() {
+			fixed--
+			if len(args) < fixed {
+				return nil, fmt.Errorf("wrong number of arguments to %s", name)
+			}
+		}
+		in := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			var paramType reflect.Type
+			if i < fixed {
+				paramType = fnType.In(i)
+			} else {
+				paramType = fnType.In(fnType.NumIn() - 1).Elem()
+			}
+			converted, err := fromLisp(primaryValue(arg), paramType)
+			if err != nil {
+				return nil, fmt.Errorf("invalid argument to %s: %v", name, err)
+			}
+			in[i] = converted
+		}
+
+		out := fnVal.Call(in)
+		if returnsError {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				return nil, err
+			}
+			out = out[:len(out)-1]
+		}
+		vals := make([]LispValue, 0, len(out))
+		for _, result := range out {
+			val, err := toLisp(result)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, val)
+		}
+		switch len(vals) {
+		case 0:
+			return &LispNil{}, nil
+		case 1:
+			return vals[0], nil
+		}
+		return &LispValues{Values: vals}, nil
+	}, doc, arity)
+	return nil
+}
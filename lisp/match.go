@@ -0,0 +1,334 @@
+package lisp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// callPredicate calls a match guard on a value. A symbol names a builtin or
+// a function, and any other form evaluates to a function.
+func callPredicate(env Environment, pred LispValue, val LispValue) (bool, error) {
+	var result LispValue
+	var err error
+	if atom, ok := pred.(*LispAtom); ok {
+		quoted := &LispList{Elements: []LispValue{&LispAtom{Value: QUOTE}, val}}
+		result, err = Eval(env, &LispList{Elements: []LispValue{atom, quoted}})
+	} else {
+		var fn LispValue
+		if fn, err = Eval(env, pred); err == nil {
+			result, err = applyFunction(fn, []LispValue{val})
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	return isTrue(primaryValue(result)), nil
+}
+
+// listElements returns the elements of a list value, treating nil as the empty list
+func listElements(val LispValue) ([]LispValue, bool) {
+	switch v := val.(type) {
+	case *LispList:
+		return v.Elements, true
+	case *LispNil:
+		return nil, true
+	}
+	return nil, false
+}
+
+// splitDotted splits a pattern of the form (p... . rest) into its fixed
+// patterns and its rest pattern, which is nil for a proper list pattern
+func splitDotted(patterns []LispValue) ([]LispValue, LispValue) {
+	n := len(patterns)
+	if n >= 2 {
+		if dot, ok := patterns[n-2].(*LispAtom); ok && dot.Value == DOT {
+			return patterns[:n-2], patterns[n-1]
+		}
+	}
+	return patterns, nil
+}
+
+// matchPattern matches a value against a pattern, recording the variables
+// it binds. Symbols bind, _ matches anything, keywords and other atoms are
+// literals, and lists are list patterns unless they start with quote, ? or
+// struct.
+func matchPattern(env Environment, pattern, val LispValue, bindings map[string]LispValue) (bool, error) {
+	switch p := pattern.(type) {
+	case *LispAtom:
+		if p.Value == WILDCARD_PATTERN {
+			return true, nil
+		}
+		if strings.HasPrefix(p.Value, KEYWORD_PREFIX) {
+			return valuesEqual(p, val), nil
+		}
+		bindings[p.Value] = val
+		return true, nil
+	case *LispList:
+		if len(p.Elements) > 0 {
+			if head, ok := p.Elements[0].(*LispAtom); ok {
+				switch head.Value {
+				case QUOTE:
+					if len(p.Elements) != 2 {
+						return false, fmt.Errorf("invalid quote pattern: %v", p)
+					}
+					return valuesEqual(p.Elements[1], val), nil
+				case PREDICATE_PATTERN:
+					return matchPredicate(env, p, val, bindings)
+				case STRUCT_PATTERN:
+					return matchStruct(env, p, val, bindings)
+				}
+			}
+		}
+		elements, ok := listElements(val)
+		if !ok {
+			return false, nil
+		}
+		fixed, rest := splitDotted(p.Elements)
+		if len(elements) < len(fixed) || (rest == nil && len(elements) != len(fixed)) {
+			return false, nil
+		}
+		for i, sub := range fixed {
+			if matched, err := matchPattern(env, sub, elements[i], bindings); !matched || err != nil {
+				return false, err
+			}
+		}
+		if rest != nil {
+			return matchPattern(env, rest, &LispList{Elements: append([]LispValue{}, elements[len(fixed):]...)}, bindings)
+		}
+		return true, nil
+	}
+	return valuesEqual(pattern, val), nil
+}
+
+// matchPredicate matches a guard pattern (? predicate [pattern])
+func matchPredicate(env Environment, p *LispList, val LispValue, bindings map[string]LispValue) (bool, error) {
+	if len(p.Elements) < 2 || len(p.Elements) > 3 {
+		return false, fmt.Errorf("invalid predicate pattern: %v", p)
+	}
+	holds, err := callPredicate(env, p.Elements[1], val)
+	if !holds || err != nil {
+		return false, err
+	}
+	if len(p.Elements) == 3 {
+		return matchPattern(env, p.Elements[2], val, bindings)
+	}
+	return true, nil
+}
+
+// matchStruct matches a record pattern (struct type pattern...), whose
+// patterns match the slots in the order they were defined
+func matchStruct(env Environment, p *LispList, val LispValue, bindings map[string]LispValue) (bool, error) {
+	if len(p.Elements) < 2 {
+		return false, fmt.Errorf("invalid struct pattern: %v", p)
+	}
+	typeName, ok := p.Elements[1].(*LispAtom)
+	if !ok {
+		return false, fmt.Errorf("invalid struct pattern: %v", p)
+	}
+	record, ok := val.(*LispRecord)
+	if !ok || record.Type.Name != typeName.Value {
+		return false, nil
+	}
+	patterns := p.Elements[2:]
+	if len(patterns) != len(record.Values) {
+		return false, fmt.Errorf("struct pattern %v doesn't have one pattern per slot of %s", p, typeName.Value)
+	}
+	for i, sub := range patterns {
+		if matched, err := matchPattern(env, sub, record.Values[i], bindings); !matched || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// builtinMatch is built-in implementation of match. It evaluates an
+// expression and runs the body of the first clause whose pattern matches
+// the value, with the pattern variables bound. It signals a match-error
+// holding the value when no clause matches.
+//
+//	(match expression (pattern form...)...)
+func builtinMatch(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("wrong number of arguments to match")
+	}
+	val, err := Eval(env, args[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, clause := range args[1:] {
+		clauseList, ok := clause.(*LispList)
+		if !ok || len(clauseList.Elements) < 1 {
+			return nil, fmt.Errorf("invalid match clause: %v", clause)
+		}
+		bindings := map[string]LispValue{}
+		matched, err := matchPattern(env, clauseList.Elements[0], val, bindings)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			continue
+		}
+		localEnv := newLocalEnvironment(env)
+		for name, bound := range bindings {
+			localEnv[name] = bound
+		}
+		return evalBody(localEnv, clauseList.Elements[1:])
+	}
+	return nil, &ConditionError{Condition: &LispCondition{
+		Type:      MATCH_ERROR_TYPE,
+		Message:   "no matching clause for",
+		Irritants: []LispValue{val},
+	}}
+}
+
+// destructure binds the variables of a destructuring lambda list to the
+// parts of a value. Lambda lists can be nested and dotted, and can use
+// &optional, &rest, &body and &key with (variable default) specifications.
+func destructure(env Environment, pattern, val LispValue) error {
+	if atom, ok := pattern.(*LispAtom); ok {
+		env[atom.Value] = val
+		return nil
+	}
+	patternList, ok := pattern.(*LispList)
+	elements, isList := listElements(val)
+	if !ok || !isList {
+		return fmt.Errorf("%v doesn't match the lambda list %v", val, pattern)
+	}
+	fixed, rest := splitDotted(patternList.Elements)
+	mode := ""
+	hasRest := false
+	i := 0
+	for _, sub := range fixed {
+		if marker, ok := sub.(*LispAtom); ok {
+			switch marker.Value {
+			case OPTIONAL_MARKER, REST_MARKER, BODY_MARKER, KEY_MARKER:
+				mode = marker.Value
+				continue
+			}
+		}
+		switch mode {
+		case "":
+			if i >= len(elements) {
+				return fmt.Errorf("%v has too few elements for the lambda list %v", val, pattern)
+			}
+			if err := destructure(env, sub, elements[i]); err != nil {
+				return err
+			}
+			i++
+		case OPTIONAL_MARKER:
+			name, defaultForm, err := optionalSpec(sub)
+			if err != nil {
+				return err
+			}
+			if i < len(elements) {
+				env[name] = elements[i]
+				i++
+			} else if err := bindDefault(env, name, defaultForm); err != nil {
+				return err
+			}
+		case REST_MARKER, BODY_MARKER:
+			if err := destructure(env, sub, &LispList{Elements: append([]LispValue{}, elements[i:]...)}); err != nil {
+				return err
+			}
+			hasRest = true
+		case KEY_MARKER:
+			name, defaultForm, err := optionalSpec(sub)
+			if err != nil {
+				return err
+			}
+			if err := bindKey(env, name, defaultForm, elements[i:]); err != nil {
+				return err
+			}
+		}
+	}
+	if mode == KEY_MARKER {
+		return checkKeys(fixed, elements[i:])
+	}
+	if rest != nil {
+		return destructure(env, rest, &LispList{Elements: append([]LispValue{}, elements[i:]...)})
+	}
+	if !hasRest && i < len(elements) {
+		return fmt.Errorf("%v has too many elements for the lambda list %v", val, pattern)
+	}
+	return nil
+}
+
+// optionalSpec parses an &optional or &key specification: var or (var default)
+func optionalSpec(spec LispValue) (string, LispValue, error) {
+	switch v := spec.(type) {
+	case *LispAtom:
+		return v.Value, nil, nil
+	case *LispList:
+		if len(v.Elements) == 2 {
+			if name, ok := v.Elements[0].(*LispAtom); ok {
+				return name.Value, v.Elements[1], nil
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("invalid lambda list parameter: %v", spec)
+}
+
+// bindDefault binds a variable to the value of its default form, or to nil
+func bindDefault(env Environment, name string, defaultForm LispValue) error {
+	if defaultForm == nil {
+		env[name] = &LispNil{}
+		return nil
+	}
+	val, err := Eval(env, defaultForm)
+	if err != nil {
+		return err
+	}
+	env[name] = val
+	return nil
+}
+
+// bindKey binds a &key variable from a list of keyword and value pairs
+func bindKey(env Environment, name string, defaultForm LispValue, plist []LispValue) error {
+	if len(plist)%2 != 0 {
+		return fmt.Errorf("odd number of keyword arguments: %v", &LispList{Elements: plist})
+	}
+	for i := 0; i < len(plist); i += 2 {
+		if plist[i].String() == KEYWORD_PREFIX+name {
+			env[name] = plist[i+1]
+			return nil
+		}
+	}
+	return bindDefault(env, name, defaultForm)
+}
+
+// checkKeys checks that every keyword of a list of keyword arguments is declared after &key
+func checkKeys(fixed []LispValue, plist []LispValue) error {
+	declared := map[string]bool{}
+	for _, spec := range fixed {
+		if name, _, err := optionalSpec(spec); err == nil {
+			declared[KEYWORD_PREFIX+name] = true
+		}
+	}
+	for i := 0; i < len(plist); i += 2 {
+		if !declared[plist[i].String()] {
+			return fmt.Errorf("unknown keyword argument: %v", plist[i])
+		}
+	}
+	return nil
+}
+
+// builtinDestructuringBind is built-in implementation of destructuring-bind.
+// It binds the variables of a lambda list to the parts of a value and
+// evaluates a body, and signals an error showing the value if it doesn't
+// have the shape of the lambda list.
+//
+//	(destructuring-bind lambda-list expression form...)
+func builtinDestructuringBind(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("wrong number of arguments to destructuring-bind")
+	}
+	val, err := Eval(env, args[1])
+	if err != nil {
+		return nil, err
+	}
+	localEnv := newLocalEnvironment(env)
+	if err := destructure(localEnv, args[0], val); err != nil {
+		return nil, err
+	}
+	return evalBody(localEnv, args[2:])
+}
@@ -0,0 +1,213 @@
+package lisp
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// floatFunctions maps the single argument float builtins to their implementation
+var floatFunctions = map[string]func(float64) float64{
+	SIN:   math.Sin,
+	COS:   math.Cos,
+	TAN:   math.Tan,
+	ASIN:  math.Asin,
+	ACOS:  math.Acos,
+	EXP:   math.Exp,
+	LOG2:  math.Log2,
+	LOG10: math.Log10,
+}
+
+// builtinFloatFunction is built-in implementation of sin, cos, tan, asin, acos, exp, log2 and log10
+func builtinFloatFunction(env Environment, args []LispValue, name string) (LispValue, error) {
+	if len(args) != 1 {
+		return nil, &LispError{Message: fmt.Sprintf("wrong number of arguments to %s", name), Line: 0, Column: 0}
+	}
+	nums, err := evalNumbers(env, args, name)
+	if err != nil {
+		return nil, err
+	}
+	return &LispFloat{Value: floatFunctions[name](toFloat(nums[0]))}, nil
+}
+
+// builtinAtan is built-in implementation of atan operation. With two arguments it behaves like atan2.
+func builtinAtan(env Environment, args []LispValue, name string) (LispValue, error) {
+	if len(args) < 1 || len(args) > 2 || (name == ATAN2 && len(args) != 2) {
+		return nil, &LispError{Message: fmt.Sprintf("wrong number of arguments to %s", name), Line: 0, Column: 0}
+	}
+	nums, err := evalNumbers(env, args, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(nums) == 2 {
+		return &LispFloat{Value: math.Atan2(toFloat(nums[0]), toFloat(nums[1]))}, nil
+	}
+	return &LispFloat{Value: math.Atan(toFloat(nums[0]))}, nil
+}
+
+// builtinLog is built-in implementation of log operation. The optional second argument is the base.
+func builtinLog(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, &LispError{Message: "wrong number of arguments to log", Line: 0, Column: 0}
+	}
+	nums, err := evalNumbers(env, args, LOG)
+	if err != nil {
+		return nil, err
+	}
+	result := math.Log(toFloat(nums[0]))
+	if len(nums) == 2 {
+		result /= math.Log(toFloat(nums[1]))
+	}
+	return &LispFloat{Value: result}, nil
+}
+
+// evalIntegers evaluates the arguments of an integer builtin and checks that
+// every one of them is an exact integer
+func evalIntegers(env Environment, args []LispValue, name string) ([]*big.Int, error) {
+	nums, err := evalNumbers(env, args, name)
+	if err != nil {
+		return nil, err
+	}
+	ints := make([]*big.Int, 0, len(nums))
+	for _, num := range nums {
+		r := toRat(num)
+		if !isExact(num) || !r.IsInt() {
+			return nil, &LispError{Message: fmt.Sprintf("invalid argument to %s: %v is not an integer", name, num), Line: 0, Column: 0}
+		}
+		ints = append(ints, new(big.Int).Set(r.Num()))
+	}
+	return ints, nil
+}
+
+// builtinGcdLcm is built-in implementation of gcd and lcm operations
+func builtinGcdLcm(env Environment, args []LispValue, name string) (LispValue, error) {
+	ints, err := evalIntegers(env, args, name)
+	if err != nil {
+		return nil, err
+	}
+	result := big.NewInt(0)
+	if name == LCM {
+		result.SetInt64(1)
+	}
+	for _, n := range ints {
+		n.Abs(n)
+		gcd := new(big.Int).GCD(nil, nil, result, n)
+		if name == GCD {
+			result = gcd
+		} else if n.Sign() == 0 {
+			result.SetInt64(0)
+		} else if result.Sign() != 0 {
+			result.Mul(result, n.Quo(n, gcd))
+		}
+	}
+	return makeInteger(result), nil
+}
+
+// builtinIntegerDivision is built-in implementation of quotient, remainder and modulo operations.
+// quotient truncates towards zero, remainder takes the sign of the dividend and modulo the sign of the divisor.
+func builtinIntegerDivision(env Environment, args []LispValue, name string) (LispValue, error) {
+	if len(args) != 2 {
+		return nil, &LispError{Message: fmt.Sprintf("wrong number of arguments to %s", name), Line: 0, Column: 0}
+	}
+	ints, err := evalIntegers(env, args, name)
+	if err != nil {
+		return nil, err
+	}
+	a, b := ints[0], ints[1]
+	if b.Sign() == 0 {
+		return nil, fmt.Errorf("division by zero")
+	}
+	switch name {
+	case QUOTIENT:
+		return makeInteger(new(big.Int).Quo(a, b)), nil
+	case REMAINDER:
+		return makeInteger(new(big.Int).Rem(a, b)), nil
+	default:
+		r := new(big.Int).Rem(a, b)
+		if r.Sign() != 0 && r.Sign() != b.Sign() {
+			r.Add(r, b)
+		}
+		return makeInteger(r), nil
+	}
+}
+
+// builtinIsqrt is built-in implementation of isqrt operation. It returns the largest integer whose square does not exceed the argument.
+func builtinIsqrt(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) != 1 {
+		return nil, &LispError{Message: "wrong number of arguments to isqrt", Line: 0, Column: 0}
+	}
+	ints, err := evalIntegers(env, args, ISQRT)
+	if err != nil {
+		return nil, err
+	}
+	if ints[0].Sign() < 0 {
+		return nil, &LispError{Message: "cannot take square root of negative number", Line: 0, Column: 0}
+	}
+	if err := interpreterOf(env).bignumWork(ints[0].BitLen() / 64); err != nil {
+		return nil, err
+	}
+	return makeInteger(new(big.Int).Sqrt(ints[0])), nil
+}
+
+// builtinBitwise is built-in implementation of logand, logior and logxor operations.
+// Negative integers behave as if they were in infinite two's complement.
+func builtinBitwise(env Environment, args []LispValue, name string) (LispValue, error) {
+	ints, err := evalIntegers(env, args, name)
+	if err != nil {
+		return nil, err
+	}
+	result := big.NewInt(0)
+	if name == LOGAND {
+		result.SetInt64(-1)
+	}
+	for _, n := range ints {
+		switch name {
+		case LOGAND:
+			result.And(result, n)
+		case LOGIOR:
+			result.Or(result, n)
+		default:
+			result.Xor(result, n)
+		}
+	}
+	return makeInteger(result), nil
+}
+
+// builtinLognot is built-in implementation of lognot operation
+func builtinLognot(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) != 1 {
+		return nil, &LispError{Message: "wrong number of arguments to lognot", Line: 0, Column: 0}
+	}
+	ints, err := evalIntegers(env, args, LOGNOT)
+	if err != nil {
+		return nil, err
+	}
+	return makeInteger(new(big.Int).Not(ints[0])), nil
+}
+
+// builtinAsh is built-in implementation of ash operation. It shifts left for a positive count and right for a negative one.
+func builtinAsh(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) != 2 {
+		return nil, &LispError{Message: "wrong number of arguments to ash", Line: 0, Column: 0}
+	}
+	ints, err := evalIntegers(env, args, ASH)
+	if err != nil {
+		return nil, err
+	}
+	if !ints[1].IsInt64() || ints[1].Int64() > math.MaxInt32 {
+		return nil, &LispError{Message: fmt.Sprintf("shift count too large: %v", ints[1]), Line: 0, Column: 0}
+	}
+	count := ints[1].Int64()
+	if count > 0 {
+		if err := interpreterOf(env).reserve(ints[0].BitLen() + int(count)); err != nil {
+			return nil, err
+		}
+	}
+	if count < 0 {
+		if count < math.MinInt32 {
+			count = math.MinInt32
+		}
+		return makeInteger(new(big.Int).Rsh(ints[0], uint(-count))), nil
+	}
+	return makeInteger(new(big.Int).Lsh(ints[0], uint(count))), nil
+}
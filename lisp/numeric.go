@@ -0,0 +1,383 @@
+package lisp
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Ranks of the numeric tower. An operation on two numbers is carried out at
+// the higher rank of its operands, so an integer combined with a rational
+// yields a rational and anything combined with a float yields a float.
+const (
+	rankInteger = iota
+	rankRational
+	rankFloat
+)
+
+// numberRank returns the rank of a numeric value in the numeric tower
+func numberRank(val LispValue) (int, bool) {
+	switch val.(type) {
+	case *LispNumber:
+		return rankInteger, true
+	case *LispRational:
+		return rankRational, true
+	case *LispFloat:
+		return rankFloat, true
+	}
+	return 0, false
+}
+
+// isExact reports whether a numeric value is exact (integer or rational)
+func isExact(val LispValue) bool {
+	rank, ok := numberRank(val)
+	return ok && rank != rankFloat
+}
+
+// toRat converts an exact numeric value to a rational
+func toRat(val LispValue) *big.Rat {
+	switch v := val.(type) {
+	case *LispNumber:
+		return new(big.Rat).SetInt64(int64(v.Value))
+	case *LispRational:
+		return new(big.Rat).Set(v.Value)
+	}
+	return new(big.Rat)
+}
+
+// toFloat converts a numeric value to a float
+func toFloat(val LispValue) float64 {
+	switch v := val.(type) {
+	case *LispNumber:
+		return float64(v.Value)
+	case *LispRational:
+		f, _ := v.Value.Float64()
+		return f
+	case *LispFloat:
+		return v.Value
+	}
+	return 0
+}
+
+// makeRational returns the canonical exact value for a rational, which is an
+// integer whenever the denominator is one and the numerator fits in an int
+func makeRational(r *big.Rat) LispValue {
+	if r.IsInt() && r.Num().IsInt64() {
+		n := r.Num().Int64()
+		if int64(int(n)) == n {
+			return &LispNumber{Value: int(n)}
+		}
+	}
+	return &LispRational{Value: r}
+}
+
+// makeInteger returns the canonical exact value for an integer
+func makeInteger(i *big.Int) LispValue {
+	return makeRational(new(big.Rat).SetInt(i))
+}
+
+// numericOp applies a binary arithmetic operator following the contagion rules
+func numericOp(op string, a, b LispValue) (LispValue, error) {
+	rankA, ok := numberRank(a)
+	if !ok {
+		return nil, &LispError{Message: fmt.Sprintf("invalid argument to %s: %v", op, a), Line: 0, Column: 0}
+	}
+	rankB, ok := numberRank(b)
+	if !ok {
+		return nil, &LispError{Message: fmt.Sprintf("invalid argument to %s: %v", op, b), Line: 0, Column: 0}
+	}
+
+	switch max(rankA, rankB) {
+	case rankInteger:
+		x, y := a.(*LispNumber).Value, b.(*LispNumber).Value
+		switch op {
+		case PLUS:
+			if sum := x + y; (sum > x) == (y > 0) {
+				return &LispNumber{Value: sum}, nil
+			}
+		case MINUS:
+			if diff := x - y; (diff < x) == (y > 0) {
+				return &LispNumber{Value: diff}, nil
+			}
+		case STAR:
+			if prod := x * y; x == 0 || (prod/x == y && !(x == -1 && y == math.MinInt)) {
+				return &LispNumber{Value: prod}, nil
+			}
+		case SLASH:
+			if y == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			if x%y == 0 && !(x == math.MinInt && y == -1) {
+				return &LispNumber{Value: x / y}, nil
+			}
+			return makeRational(big.NewRat(int64(x), int64(y))), nil
+		case PERCENT:
+			if y == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return &LispNumber{Value: x % y}, nil
+		}
+		// the result overflows an int, so it is computed with big integers
+		return numericOp(op, &LispRational{Value: toRat(a)}, b)
+	case rankRational:
+		x, y := toRat(a), toRat(b)
+		switch op {
+		case PLUS:
+			return makeRational(x.Add(x, y)), nil
+		case MINUS:
+			return makeRational(x.Sub(x, y)), nil
+		case STAR:
+			return makeRational(x.Mul(x, y)), nil
+		case SLASH:
+			if y.Sign() == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return makeRational(x.Quo(x, y)), nil
+		case PERCENT:
+			if y.Sign() == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			// the remainder of the quotient truncated towards zero
+			q := new(big.Rat).Quo(x, y)
+			truncated := new(big.Rat).SetInt(new(big.Int).Quo(q.Num(), q.Denom()))
+			return makeRational(x.Sub(x, truncated.Mul(truncated, y))), nil
+		}
+	case rankFloat:
+		if (op == SLASH || op == PERCENT) && isExact(b) && toRat(b).Sign() == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		x, y := toFloat(a), toFloat(b)
+		switch op {
+		case PLUS:
+			return &LispFloat{Value: x + y}, nil
+		case MINUS:
+			return &LispFloat{Value: x - y}, nil
+		case STAR:
+			return &LispFloat{Value: x * y}, nil
+		case SLASH:
+			return &LispFloat{Value: x / y}, nil
+		case PERCENT:
+			return &LispFloat{Value: math.Mod(x, y)}, nil
+		}
+	}
+	return nil, &LispError{Message: fmt.Sprintf("unknown numeric operator: %s", op), Line: 0, Column: 0}
+}
+
+// compareNumbers compares two numeric values. The second result is false
+// when the values are unordered, which only happens when a NaN is involved.
+func compareNumbers(a, b LispValue) (int, bool) {
+	rankA, _ := numberRank(a)
+	rankB, _ := numberRank(b)
+	switch max(rankA, rankB) {
+	case rankInteger:
+		x, y := a.(*LispNumber).Value, b.(*LispNumber).Value
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	case rankRational:
+		return toRat(a).Cmp(toRat(b)), true
+	}
+	x, y := toFloat(a), toFloat(b)
+	switch {
+	case math.IsNaN(x) || math.IsNaN(y):
+		return 0, false
+	case x < y:
+		return -1, true
+	case x > y:
+		return 1, true
+	}
+	return 0, true
+}
+
+// evalNumbers evaluates the arguments of a numeric builtin and checks that
+// every one of them is a number
+func evalNumbers(env Environment, args []LispValue, name string) ([]LispValue, error) {
+	nums := make([]LispValue, 0, len(args))
+	for _, arg := range args {
+		val, err := Eval(env, arg)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := numberRank(val); !ok {
+			return nil, &LispError{Message: fmt.Sprintf("invalid argument to %s: %v", name, val), Line: 0, Column: 0}
+		}
+		nums = append(nums, val)
+	}
+	return nums, nil
+}
+
+// foldNumbers combines numbers from left to right with a numeric operator
+func foldNumbers(op string, acc LispValue, nums []LispValue) (LispValue, error) {
+	var err error
+	for _, num := range nums {
+		acc, err = numericOp(op, acc, num)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return acc, nil
+}
+
+// compareChain checks that the comparison holds for every adjacent pair of
+// arguments, which gives the variadic forms such as (< 1 2 3)
+func compareChain(env Environment, args []LispValue, name string, holds func(cmp int) bool) (LispValue, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("wrong number of arguments to %s", name)
+	}
+	nums, err := evalNumbers(env, args, name)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(nums); i++ {
+		cmp, ordered := compareNumbers(nums[i-1], nums[i])
+		if !ordered || !holds(cmp) {
+			return &LispAtom{Value: FALSE}, nil
+		}
+	}
+	return &LispAtom{Value: TRUE}, nil
+}
+
+// roundNumber rounds a number to an integral value using the given mode.
+// Exact arguments give exact results and floats give floats.
+func roundNumber(val LispValue, mode string) LispValue {
+	switch v := val.(type) {
+	case *LispNumber:
+		return v
+	case *LispFloat:
+		switch mode {
+		case FLOOR:
+			return &LispFloat{Value: math.Floor(v.Value)}
+		case CEILING:
+			return &LispFloat{Value: math.Ceil(v.Value)}
+		case ROUND:
+			return &LispFloat{Value: math.RoundToEven(v.Value)}
+		default:
+			return &LispFloat{Value: math.Trunc(v.Value)}
+		}
+	}
+
+	r := toRat(val)
+	if r.IsInt() {
+		return val
+	}
+	num, den := r.Num(), r.Denom()
+	// big.Int.Div rounds towards negative infinity for a positive divisor,
+	// and rational denominators are always positive
+	floor := new(big.Int).Div(num, den)
+	switch mode {
+	case FLOOR:
+		return makeInteger(floor)
+	case CEILING:
+		return makeInteger(floor.Add(floor, big.NewInt(1)))
+	case ROUND:
+		frac := new(big.Rat).Sub(r, new(big.Rat).SetInt(floor))
+		switch frac.Cmp(big.NewRat(1, 2)) {
+		case 1:
+			floor.Add(floor, big.NewInt(1))
+		case 0:
+			if floor.Bit(0) == 1 {
+				floor.Add(floor, big.NewInt(1))
+			}
+		}
+		return makeInteger(floor)
+	default:
+		return makeInteger(new(big.Int).Quo(num, den))
+	}
+}
+
+// builtinRound is built-in implementation of floor, ceiling, round and truncate
+func builtinRound(env Environment, args []LispValue, mode string) (LispValue, error) {
+	if len(args) != 1 {
+		return nil, &LispError{Message: fmt.Sprintf("wrong number of arguments to %s", mode), Line: 0, Column: 0}
+	}
+	nums, err := evalNumbers(env, args, mode)
+	if err != nil {
+		return nil, err
+	}
+	return roundNumber(nums[0], mode), nil
+}
+
+// builtinNumNotEq is built-in implementation of /= condition. It holds when no two arguments are equal.
+func builtinNumNotEq(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("wrong number of arguments to %s", NUM_NOT_EQUAL)
+	}
+	nums, err := evalNumbers(env, args, NUM_NOT_EQUAL)
+	if err != nil {
+		return nil, err
+	}
+	for i := range nums {
+		for j := i + 1; j < len(nums); j++ {
+			if cmp, ordered := compareNumbers(nums[i], nums[j]); ordered && cmp == 0 {
+				return &LispAtom{Value: FALSE}, nil
+			}
+		}
+	}
+	return &LispAtom{Value: TRUE}, nil
+}
+
+// builtinAbs is built-in implementation of abs operation
+func builtinAbs(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) != 1 {
+		return nil, &LispError{Message: "wrong number of arguments to abs", Line: 0, Column: 0}
+	}
+	nums, err := evalNumbers(env, args, ABS)
+	if err != nil {
+		return nil, err
+	}
+	switch v := nums[0].(type) {
+	case *LispNumber:
+		if v.Value < 0 {
+			return numericOp(MINUS, &LispNumber{Value: 0}, v)
+		}
+		return v, nil
+	case *LispRational:
+		return makeRational(new(big.Rat).Abs(v.Value)), nil
+	default:
+		return &LispFloat{Value: math.Abs(toFloat(v))}, nil
+	}
+}
+
+// builtinMinMax is built-in implementation of min and max operations.
+// The result is inexact as soon as any argument is inexact.
+func builtinMinMax(env Environment, args []LispValue, name string) (LispValue, error) {
+	if len(args) < 1 {
+		return nil, &LispError{Message: fmt.Sprintf("wrong number of arguments to %s", name), Line: 0, Column: 0}
+	}
+	nums, err := evalNumbers(env, args, name)
+	if err != nil {
+		return nil, err
+	}
+	best, inexact := nums[0], !isExact(nums[0])
+	for _, num := range nums[1:] {
+		inexact = inexact || !isExact(num)
+		if math.IsNaN(toFloat(num)) && !isExact(num) {
+			best = num
+			continue
+		}
+		cmp, ordered := compareNumbers(num, best)
+		if ordered && ((name == MIN && cmp < 0) || (name == MAX && cmp > 0)) {
+			best = num
+		}
+	}
+	if inexact {
+		return &LispFloat{Value: toFloat(best)}, nil
+	}
+	return best, nil
+}
+
+// builtinIsExact is built-in implementation of exact? and inexact? predicates
+func builtinIsExact(env Environment, args []LispValue, name string) (LispValue, error) {
+	if len(args) != 1 {
+		return nil, &LispError{Message: fmt.Sprintf("wrong number of arguments to %s", name), Line: 0, Column: 0}
+	}
+	nums, err := evalNumbers(env, args, name)
+	if err != nil {
+		return nil, err
+	}
+	return &LispBoolean{Value: isExact(nums[0]) == (name == EXACT)}, nil
+}
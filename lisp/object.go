@@ -0,0 +1,695 @@
+package lisp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Names of the builtin classes
+const (
+	T_CLASS                = "t"
+	NUMBER_CLASS           = "number"
+	REAL_CLASS             = "real"
+	RATIONAL_CLASS         = "rational"
+	INTEGER_CLASS          = "integer"
+	RATIO_CLASS            = "ratio"
+	FLOAT_CLASS            = "float"
+	SEQUENCE_CLASS         = "sequence"
+	LIST_CLASS             = "list"
+	SYMBOL_CLASS           = "symbol"
+	NULL_CLASS             = "null"
+	STRING_CLASS           = "string"
+	BOOLEAN_CLASS          = "boolean"
+	FUNCTION_CLASS         = "function"
+	GENERIC_FUNCTION_CLASS = "generic-function"
+	CONDITION_CLASS        = "condition"
+	STRUCTURE_OBJECT_CLASS = "structure-object"
+	STANDARD_OBJECT_CLASS  = "standard-object"
+	CLASS_CLASS            = "class"
+)
+
+// Method qualifiers of the standard method combination
+const (
+	BEFORE_QUALIFIER = ":before"
+	AFTER_QUALIFIER  = ":after"
+	AROUND_QUALIFIER = ":around"
+)
+
+// classSlot describes a slot declared by defclass
+type classSlot struct {
+	Name     string
+	Initargs []string
+	Initform LispValue
+}
+
+// genericMethod is a method of a generic function
+type genericMethod struct {
+	Qualifier    string
+	Specializers []*LispClass
+	Params       []LispValue
+	Body         []LispValue
+	Env          Environment
+}
+
+// newClasses returns the classes of the builtin types
+func newClasses() map[string]*LispClass {
+	classes := map[string]*LispClass{}
+	builtinClasses := [][]string{
+		{T_CLASS},
+		{NUMBER_CLASS, T_CLASS},
+		{REAL_CLASS, NUMBER_CLASS},
+		{RATIONAL_CLASS, REAL_CLASS},
+		{INTEGER_CLASS, RATIONAL_CLASS},
+		{RATIO_CLASS, RATIONAL_CLASS},
+		{FLOAT_CLASS, REAL_CLASS},
+		{SEQUENCE_CLASS, T_CLASS},
+		{LIST_CLASS, SEQUENCE_CLASS},
+		{SYMBOL_CLASS, T_CLASS},
+		{NULL_CLASS, SYMBOL_CLASS, LIST_CLASS},
+		{STRING_CLASS, SEQUENCE_CLASS},
+		{BOOLEAN_CLASS, T_CLASS},
+		{FUNCTION_CLASS, T_CLASS},
+		{GENERIC_FUNCTION_CLASS, FUNCTION_CLASS},
+		{CONDITION_CLASS, T_CLASS},
+		{STRUCTURE_OBJECT_CLASS, T_CLASS},
+		{STANDARD_OBJECT_CLASS, T_CLASS},
+		{CLASS_CLASS, STANDARD_OBJECT_CLASS},
+	}
+	for _, names := range builtinClasses {
+		class := &LispClass{Name: names[0]}
+		for _, super := range names[1:] {
+			class.Supers = append(class.Supers, classes[super])
+		}
+		class.Precedence, _ = linearize(class)
+		classes[class.Name] = class
+	}
+	return classes
+}
+
+// linearize computes the class precedence list of a class with the C3
+// linearization, which keeps every class before its superclasses and the
+// superclasses in the order they are listed
+func linearize(class *LispClass) ([]*LispClass, error) {
+	sequences := make([][]*LispClass, 0, len(class.Supers)+1)
+	for _, super := range class.Supers {
+		sequences = append(sequences, append([]*LispClass{}, super.Precedence...))
+	}
+	sequences = append(sequences, append([]*LispClass{}, class.Supers...))
+
+	result := []*LispClass{class}
+	for {
+		empty := true
+		var next *LispClass
+		for _, seq := range sequences {
+			if len(seq) == 0 {
+				continue
+			}
+			empty = false
+			candidate := seq[0]
+			inTail := false
+			for _, other := range sequences {
+				for _, c := range other[min(1, len(other)):] {
+					if c == candidate {
+						inTail = true
+					}
+				}
+			}
+			if !inTail {
+				next = candidate
+				break
+			}
+		}
+		if empty {
+			return result, nil
+		}
+		if next == nil {
+			return nil, fmt.Errorf("inconsistent class precedence for %s", class.Name)
+		}
+		result = append(result, next)
+		for i, seq := range sequences {
+			if len(seq) > 0 && seq[0] == next {
+				sequences[i] = seq[1:]
+			}
+		}
+	}
+}
+
+// classOf returns the class of a value
+func (interp *Interpreter) classOf(val LispValue) *LispClass {
+	name := T_CLASS
+	switch v := val.(type) {
+	case *LispInstance:
+		return v.Class
+	case *LispRecord:
+		return interp.recordClass(v.Type)
+	case *LispNumber:
+		name = INTEGER_CLASS
+	case *LispRational:
+		name = RATIO_CLASS
+		if v.Value.IsInt() {
+			name = INTEGER_CLASS
+		}
+	case *LispFloat:
+		name = FLOAT_CLASS
+	case *LispString:
+		name = STRING_CLASS
+	case *LispAtom:
+		name = SYMBOL_CLASS
+	case *LispNil:
+		name = NULL_CLASS
+	case *LispList:
+		name = LIST_CLASS
+	case *LispBoolean:
+		name = BOOLEAN_CLASS
+	case *LispGeneric:
+		name = GENERIC_FUNCTION_CLASS
+	case *LispFunction, *LispPrimitive, *LispContinuation, *LispParameter:
+		name = FUNCTION_CLASS
+	case *LispCondition:
+		name = CONDITION_CLASS
+	case *LispClass:
+		name = CLASS_CLASS
+	}
+	return interp.classes[name]
+}
+
+// recordClass returns the class of a record type
+func (interp *Interpreter) recordClass(recordType *LispRecordType) *LispClass {
+	if class, ok := interp.recordClasses[recordType]; ok {
+		return class
+	}
+	class := &LispClass{Name: recordType.Name, Supers: []*LispClass{interp.classes[STRUCTURE_OBJECT_CLASS]}}
+	class.Precedence, _ = linearize(class)
+	interp.recordClasses[recordType] = class
+	return class
+}
+
+// isSubclass reports whether a class is another class or one of its subclasses
+func isSubclass(class, super *LispClass) bool {
+	for _, c := range class.Precedence {
+		if c == super {
+			return true
+		}
+	}
+	return false
+}
+
+// findClass returns the class named by a value
+func (interp *Interpreter) findClass(val LispValue) (*LispClass, error) {
+	if class, ok := val.(*LispClass); ok {
+		return class, nil
+	}
+	if name, ok := blockName(val); ok {
+		if class, ok := interp.classes[name]; ok {
+			return class, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown class: %v", val)
+}
+
+// parseClassSlot parses a defclass slot specification and binds the
+// readers, writers and accessors it declares
+func parseClassSlot(env Environment, class *LispClass, spec LispValue) (classSlot, error) {
+	if atom, ok := spec.(*LispAtom); ok {
+		return classSlot{Name: atom.Value}, nil
+	}
+	list, ok := spec.(*LispList)
+	if !ok || len(list.Elements)%2 != 1 {
+		return classSlot{}, fmt.Errorf("invalid slot specification: %v", spec)
+	}
+	name, ok := list.Elements[0].(*LispAtom)
+	if !ok {
+		return classSlot{}, fmt.Errorf("invalid slot specification: %v", spec)
+	}
+	slot := classSlot{Name: name.Value}
+	for i := 1; i < len(list.Elements); i += 2 {
+		value := list.Elements[i+1]
+		switch list.Elements[i].String() {
+		case INITARG_KEYWORD:
+			slot.Initargs = append(slot.Initargs, strings.TrimPrefix(value.String(), KEYWORD_PREFIX))
+		case INITFORM_KEYWORD:
+			slot.Initform = value
+		case READER_KEYWORD, ACCESSOR_KEYWORD:
+			reader := value.String()
+			defineRecordFunction(env, reader, slotReader(class, slot.Name, reader))
+		case WRITER_KEYWORD:
+			writer := value.String()
+			defineRecordFunction(env, writer, slotWriter(class, slot.Name, writer))
+		case DOCUMENTATION_KEYWORD, TYPE_KEYWORD:
+		default:
+			return classSlot{}, fmt.Errorf("invalid slot option: %v", list.Elements[i])
+		}
+	}
+	return slot, nil
+}
+
+// instanceArgument checks that an argument of a slot function is an instance of the given class
+func instanceArgument(val LispValue, class *LispClass, name string) (*LispInstance, error) {
+	instance, ok := val.(*LispInstance)
+	if !ok || !isSubclass(instance.Class, class) {
+		return nil, fmt.Errorf("invalid argument to %s: %v is not a %s", name, val, class.Name)
+	}
+	return instance, nil
+}
+
+// slotReader returns the function reading a slot of the instances of a class
+func slotReader(class *LispClass, slot, name string) func(args []LispValue) (LispValue, error) {
+	return func(args []LispValue) (LispValue, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("wrong number of arguments to %s", name)
+		}
+		instance, err := instanceArgument(args[0], class, name)
+		if err != nil {
+			return nil, err
+		}
+		return readSlot(instance, slot)
+	}
+}
+
+// slotWriter returns the function changing a slot of the instances of a
+// class. Like a setf function, it takes the new value first.
+func slotWriter(class *LispClass, slot, name string) func(args []LispValue) (LispValue, error) {
+	return func(args []LispValue) (LispValue, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("wrong number of arguments to %s", name)
+		}
+		instance, err := instanceArgument(args[1], class, name)
+		if err != nil {
+			return nil, err
+		}
+		return writeSlot(instance, slot, args[0])
+	}
+}
+
+// hasSlot reports whether the instances of a class have a slot
+func hasSlot(class *LispClass, name string) bool {
+	for _, c := range class.Precedence {
+		for _, slot := range c.Slots {
+			if slot.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readSlot returns the value of a slot of an instance
+func readSlot(instance *LispInstance, name string) (LispValue, error) {
+	if val, ok := instance.Slots[name]; ok {
+		return val, nil
+	}
+	if !hasSlot(instance.Class, name) {
+		return nil, fmt.Errorf("%v has no slot %s", instance, name)
+	}
+	return nil, fmt.Errorf("slot %s of %v is unbound", name, instance)
+}
+
+// writeSlot sets the value of a slot of an instance
+func writeSlot(instance *LispInstance, name string, val LispValue) (LispValue, error) {
+	if !hasSlot(instance.Class, name) {
+		return nil, fmt.Errorf("%v has no slot %s", instance, name)
+	}
+	instance.Slots[name] = val
+	return val, nil
+}
+
+// builtinDefclass is built-in implementation of defclass. A class without
+// superclasses inherits from standard-object.
+//
+//	(defclass name (superclass...) (slot | (slot :initarg :key :initform form :reader fn :writer fn :accessor fn)...))
+func builtinDefclass(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) < 3 {
+		return nil, fmt.Errorf("wrong number of arguments to defclass")
+	}
+	name, ok := args[0].(*LispAtom)
+	if !ok {
+		return nil, fmt.Errorf("invalid class name: %v", args[0])
+	}
+	supers, ok := args[1].(*LispList)
+	if !ok {
+		return nil, fmt.Errorf("invalid superclasses: %v", args[1])
+	}
+	slots, ok := args[2].(*LispList)
+	if !ok {
+		return nil, fmt.Errorf("invalid class slots: %v", args[2])
+	}
+
+	class := &LispClass{Name: name.Value, Env: env}
+	for _, super := range supers.Elements {
+		superClass, err := interp.findClass(super)
+		if err != nil {
+			return nil, err
+		}
+		class.Supers = append(class.Supers, superClass)
+	}
+	if len(class.Supers) == 0 {
+		class.Supers = []*LispClass{interp.classes[STANDARD_OBJECT_CLASS]}
+	}
+	precedence, err := linearize(class)
+	if err != nil {
+		return nil, err
+	}
+	class.Precedence = precedence
+	for _, spec := range slots.Elements {
+		slot, err := parseClassSlot(env, class, spec)
+		if err != nil {
+			return nil, err
+		}
+		class.Slots = append(class.Slots, slot)
+	}
+	interp.classes[name.Value] = class
+	return class, nil
+}
+
+// builtinMakeInstance is built-in implementation of make-instance. Slots are
+// filled from the initargs, then from the initforms of the most specific
+// class declaring them.
+//
+//	(make-instance 'class :initarg value...)
+func builtinMakeInstance(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) < 1 {
+		return nil, fmt.Errorf("wrong number of arguments to make-instance")
+	}
+	vals, err := evalArgs(env, args)
+	if err != nil {
+		return nil, err
+	}
+	class, err := interp.findClass(vals[0])
+	if err != nil {
+		return nil, err
+	}
+	initargs := vals[1:]
+	if len(initargs)%2 != 0 {
+		return nil, fmt.Errorf("odd number of initargs to make-instance")
+	}
+
+	instance := &LispInstance{Class: class, Slots: map[string]LispValue{}}
+	used := make([]bool, len(initargs)/2)
+	for _, c := range class.Precedence {
+		for _, slot := range c.Slots {
+			for _, initarg := range slot.Initargs {
+				for i := 0; i < len(initargs); i += 2 {
+					if strings.TrimPrefix(initargs[i].String(), KEYWORD_PREFIX) != initarg {
+						continue
+					}
+					used[i/2] = true
+					if _, done := instance.Slots[slot.Name]; !done {
+						instance.Slots[slot.Name] = initargs[i+1]
+					}
+				}
+			}
+		}
+	}
+	for i, ok := range used {
+		if !ok {
+			return nil, fmt.Errorf("invalid initarg to make-instance of %s: %v", class.Name, initargs[2*i])
+		}
+	}
+	for _, c := range class.Precedence {
+		for _, slot := range c.Slots {
+			if _, done := instance.Slots[slot.Name]; done || slot.Initform == nil {
+				continue
+			}
+			val, err := Eval(c.Env, slot.Initform)
+			if err != nil {
+				return nil, err
+			}
+			instance.Slots[slot.Name] = val
+		}
+	}
+	return instance, nil
+}
+
+// builtinSlotValue is built-in implementation of slot-value and set-slot-value operations
+//
+//	(slot-value instance 'slot)
+//	(set-slot-value instance 'slot value)
+func builtinSlotValue(env Environment, args []LispValue, name string) (LispValue, error) {
+	if (name == SLOT_VALUE && len(args) != 2) || (name == SET_SLOT_VALUE && len(args) != 3) {
+		return nil, fmt.Errorf("wrong number of arguments to %s", name)
+	}
+	vals, err := evalArgs(env, args)
+	if err != nil {
+		return nil, err
+	}
+	instance, ok := vals[0].(*LispInstance)
+	if !ok {
+		return nil, fmt.Errorf("invalid argument to %s: %v is not an instance", name, vals[0])
+	}
+	slot := strings.TrimPrefix(vals[1].String(), KEYWORD_PREFIX)
+	if name == SET_SLOT_VALUE {
+		return writeSlot(instance, slot, vals[2])
+	}
+	return readSlot(instance, slot)
+}
+
+// builtinClassOf is built-in implementation of class-of and find-class operations
+func builtinClassOf(env Environment, args []LispValue, name string) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments to %s", name)
+	}
+	val, err := Eval(env, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if name == FIND_CLASS {
+		return interp.findClass(val)
+	}
+	return interp.classOf(val), nil
+}
+
+// ensureGeneric returns the generic function bound to a name, creating it if needed
+func ensureGeneric(env Environment, name string, params []LispValue) *LispGeneric {
+	if generic, ok := env[name].(*LispGeneric); ok {
+		return generic
+	}
+	generic := &LispGeneric{Name: name, Params: params, Env: env}
+	env[name] = generic
+	return generic
+}
+
+// builtinDefgeneric is built-in implementation of defgeneric. Options such as
+// (:documentation "text") are accepted and ignored.
+//
+//	(defgeneric name (param...) option...)
+func builtinDefgeneric(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("wrong number of arguments to defgeneric")
+	}
+	name, ok := args[0].(*LispAtom)
+	if !ok {
+		return nil, fmt.Errorf("invalid generic function name: %v", args[0])
+	}
+	params, ok := args[1].(*LispList)
+	if !ok {
+		return nil, fmt.Errorf("invalid generic function parameters: %v", args[1])
+	}
+	generic := ensureGeneric(env, name.Value, params.Elements)
+	if len(generic.Params) != len(params.Elements) {
+		return nil, fmt.Errorf("parameters of %s don't match its methods", name.Value)
+	}
+	return generic, nil
+}
+
+// builtinDefmethod is built-in implementation of defmethod. A parameter is a
+// symbol, which matches any argument, or (name class), which matches the
+// instances of the class and of its subclasses. The generic function is
+// created if it doesn't exist yet.
+//
+//	(defmethod name [:before | :after | :around] (param | (param class)...) form...)
+func builtinDefmethod(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) < 2 {
+		return nil, fmt.Errorf("wrong number of arguments to defmethod")
+	}
+	name, ok := args[0].(*LispAtom)
+	if !ok {
+		return nil, fmt.Errorf("invalid generic function name: %v", args[0])
+	}
+	method := &genericMethod{Env: env}
+	rest := args[1:]
+	if qualifier, ok := rest[0].(*LispAtom); ok {
+		switch qualifier.Value {
+		case BEFORE_QUALIFIER, AFTER_QUALIFIER, AROUND_QUALIFIER:
+			method.Qualifier = qualifier.Value
+			rest = rest[1:]
+		default:
+			return nil, fmt.Errorf("invalid method qualifier: %v", qualifier)
+		}
+	}
+	if len(rest) < 1 {
+		return nil, fmt.Errorf("wrong number of arguments to defmethod")
+	}
+	params, ok := rest[0].(*LispList)
+	if !ok {
+		return nil, fmt.Errorf("invalid method parameters: %v", rest[0])
+	}
+	for _, param := range params.Elements {
+		var paramName, className LispValue = param, &LispAtom{Value: T_CLASS}
+		if list, ok := param.(*LispList); ok && len(list.Elements) == 2 {
+			paramName, className = list.Elements[0], list.Elements[1]
+		}
+		if _, ok := paramName.(*LispAtom); !ok {
+			return nil, fmt.Errorf("invalid parameter name: %v", param)
+		}
+		class, err := interp.findClass(className)
+		if err != nil {
+			return nil, err
+		}
+		method.Params = append(method.Params, paramName)
+		method.Specializers = append(method.Specializers, class)
+	}
+	method.Body = rest[1:]
+
+	generic := ensureGeneric(env, name.Value, method.Params)
+	if len(generic.Params) != len(method.Params) {
+		return nil, fmt.Errorf("parameters of method don't match generic function %s", name.Value)
+	}
+	for i, existing := range generic.Methods {
+		if existing.Qualifier == method.Qualifier && sameSpecializers(existing.Specializers, method.Specializers) {
+			generic.Methods[i] = method
+			return generic, nil
+		}
+	}
+	generic.Methods = append(generic.Methods, method)
+	return generic, nil
+}
+
+// sameSpecializers reports whether two methods specialize on the same classes
+func sameSpecializers(a, b []*LispClass) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// precedenceIndex returns the position of a class in a class precedence list, or -1
+func precedenceIndex(precedence []*LispClass, class *LispClass) int {
+	for i, c := range precedence {
+		if c == class {
+			return i
+		}
+	}
+	return -1
+}
+
+// applicableMethods returns the methods applicable to the arguments, most specific first
+func applicableMethods(generic *LispGeneric, args []LispValue) []*genericMethod {
+	precedences := make([][]*LispClass, len(args))
+	for i, arg := range args {
+		precedences[i] = interpreterOf(generic.Env).classOf(arg).Precedence
+	}
+	var methods []*genericMethod
+	for _, method := range generic.Methods {
+		applicable := true
+		for i, class := range method.Specializers {
+			if precedenceIndex(precedences[i], class) < 0 {
+				applicable = false
+				break
+			}
+		}
+		if applicable {
+			methods = append(methods, method)
+		}
+	}
+	sort.SliceStable(methods, func(a, b int) bool {
+		for i := range args {
+			indexA := precedenceIndex(precedences[i], methods[a].Specializers[i])
+			indexB := precedenceIndex(precedences[i], methods[b].Specializers[i])
+			if indexA != indexB {
+				return indexA < indexB
+			}
+		}
+		return false
+	})
+	return methods
+}
+
+// callMethod evaluates the body of a method. next is the effective method
+// call-next-method invokes, or nil when the method has no next method.
+func callMethod(generic *LispGeneric, method *genericMethod, args []LispValue, next func(args []LispValue) (LispValue, error)) (LispValue, error) {
+	localEnv := newLocalEnvironment(method.Env)
+	interp := interpreterOf(localEnv)
+	var restore []func()
+	defer unbind(&restore)
+	for i, param := range method.Params {
+		interp.bindVariable(localEnv, param.(*LispAtom).Value, args[i], &restore)
+	}
+	if method.Qualifier != BEFORE_QUALIFIER && method.Qualifier != AFTER_QUALIFIER {
+		defineRecordFunction(localEnv, CALL_NEXT_METHOD, func(nextArgs []LispValue) (LispValue, error) {
+			if next == nil {
+				return nil, fmt.Errorf("no next method for %s", generic.Name)
+			}
+			if len(nextArgs) == 0 {
+				nextArgs = args
+			}
+			return next(nextArgs)
+		})
+		defineRecordFunction(localEnv, NEXT_METHOD_P, func(nextArgs []LispValue) (LispValue, error) {
+			return &LispBoolean{Value: next != nil}, nil
+		})
+	}
+	return evalBlock(localEnv, generic.Name, method.Body)
+}
+
+// chainMethods returns the effective method calling methods[i], whose next
+// method is methods[i+1], and last after the final one
+func chainMethods(generic *LispGeneric, methods []*genericMethod, i int, last func(args []LispValue) (LispValue, error)) func(args []LispValue) (LispValue, error) {
+	if i == len(methods) {
+		return last
+	}
+	return func(args []LispValue) (LispValue, error) {
+		return callMethod(generic, methods[i], args, chainMethods(generic, methods, i+1, last))
+	}
+}
+
+// call applies a generic function with the standard method combination: the
+// around methods wrap the before methods, most specific first, the primary
+// methods, chained by call-next-method, and the after methods, least
+// specific first. The result is the one of the primary methods.
+func (g *LispGeneric) call(args []LispValue) (LispValue, error) {
+	if len(args) != len(g.Params) {
+		return nil, fmt.Errorf("wrong number of arguments to %s", g.Name)
+	}
+	var arounds, befores, primaries, afters []*genericMethod
+	for _, method := range applicableMethods(g, args) {
+		switch method.Qualifier {
+		case AROUND_QUALIFIER:
+			arounds = append(arounds, method)
+		case BEFORE_QUALIFIER:
+			befores = append(befores, method)
+		case AFTER_QUALIFIER:
+			afters = append([]*genericMethod{method}, afters...)
+		default:
+			primaries = append(primaries, method)
+		}
+	}
+	if len(primaries) == 0 {
+		return nil, fmt.Errorf("no applicable method for %s with arguments %v", g.Name, &LispList{Elements: args})
+	}
+
+	standard := func(args []LispValue) (LispValue, error) {
+		for _, method := range befores {
+			if _, err := callMethod(g, method, args, nil); err != nil {
+				return nil, err
+			}
+		}
+		result, err := chainMethods(g, primaries, 0, nil)(args)
+		if err != nil {
+			return nil, err
+		}
+		for _, method := range afters {
+			if _, err := callMethod(g, method, args, nil); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	return chainMethods(g, arounds, 0, standard)(args)
+}
@@ -0,0 +1,96 @@
+package lisp
+
+import (
+	"container/list"
+	"crypto/sha256"
+)
+
+// PARSE_CACHE_SIZE is the number of source texts whose parse an interpreter keeps
+const PARSE_CACHE_SIZE = 256
+
+// parseCache keeps the forms parsed from the most recently read source texts,
+// keyed by the hash of the whole text. It stores and hands out copies, so
+// evaluating or changing a form never alters the cached one.
+type parseCache struct {
+	capacity int
+	// entries maps the hashes of the source texts to their elements in order
+	entries map[[sha256.Size]byte]*list.Element
+	// order holds the entries, most recently used first
+	order *list.List
+}
+
+// parseEntry holds the forms of a source text in the parse cache
+type parseEntry struct {
+	key   [sha256.Size]byte
+	forms []LispValue
+}
+
+// newParseCache returns an empty parse cache keeping capacity source texts
+func newParseCache(capacity int) *parseCache {
+	return &parseCache{
+		capacity: capacity,
+		entries:  make(map[[sha256.Size]byte]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// get returns a copy of the forms parsed from a source text
+func (c *parseCache) get(src string) ([]LispValue, bool) {
+	elem, ok := c.entries[sha256.Sum256([]byte(src))]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return cloneForms(elem.Value.(*parseEntry).forms), true
+}
+
+// put stores a copy of the forms parsed from a source text, evicting the
+// least recently used text when the cache is full
+func (c *parseCache) put(src string, forms []LispValue) {
+	key := sha256.Sum256([]byte(src))
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*parseEntry).forms = cloneForms(forms)
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&parseEntry{key: key, forms: cloneForms(forms)})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*parseEntry).key)
+	}
+}
+
+// len returns the number of source texts in the cache
+func (c *parseCache) len() int {
+	return c.order.Len()
+}
+
+// cloneForms copies parsed forms
+func cloneForms(forms []LispValue) []LispValue {
+	clones := make([]LispValue, len(forms))
+	for i, form := range forms {
+		clones[i] = cloneExpr(form)
+	}
+	return clones
+}
+
+// cloneExpr copies the lists, strings and atoms of a parsed expression.
+// Numbers, booleans and nil are immutable and shared.
+func cloneExpr(expr LispValue) LispValue {
+	switch v := expr.(type) {
+	case *LispList:
+		elements := make([]LispValue, len(v.Elements))
+		for i, elem := range v.Elements {
+			elements[i] = cloneExpr(elem)
+		}
+		return &LispList{Elements: elements, Line: v.Line, Column: v.Column}
+	case *LispString:
+		return &LispString{Value: v.Value}
+	case *LispAtom:
+		clone := *v
+		return &clone
+	default:
+		return expr
+	}
+}
@@ -0,0 +1,196 @@
+package lisp
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxError is a syntax error of source text, spanning from Line and Column
+// to EndLine and EndColumn, exclusive
+type SyntaxError struct {
+	Message   string
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
+}
+
+// Error returns the error message
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("Syntax error at line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// SyntaxErrors lists the syntax errors of source text in the order they appear
+type SyntaxErrors []*SyntaxError
+
+// Error returns the messages of the errors, one per line
+func (errs SyntaxErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// sortSyntaxErrors orders syntax errors by position
+func sortSyntaxErrors(errs SyntaxErrors) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Line != errs[j].Line {
+			return errs[i].Line < errs[j].Line
+		}
+		return errs[i].Column < errs[j].Column
+	})
+}
+
+// tokenSource yields tokens one at a time, and io.EOF after the last one
+type tokenSource interface {
+	Next() (Token, error)
+}
+
+// tokenSlice is a token source over a slice of tokens
+type tokenSlice struct {
+	tokens []Token
+}
+
+// Next returns the next token of the slice
+func (s *tokenSlice) Next() (Token, error) {
+	if len(s.tokens) == 0 {
+		return Token{}, io.EOF
+	}
+	token := s.tokens[0]
+	s.tokens = s.tokens[1:]
+	return token, nil
+}
+
+// parser constructs expressions from the tokens of a source. It records the
+// syntax errors it finds and carries on after them, so a single pass finds
+// all of them.
+type parser struct {
+	source tokenSource
+	// unread holds a token to read again, unless it is nil
+	unread *Token
+	errors SyntaxErrors
+}
+
+// next returns the next token
+func (p *parser) next() (Token, error) {
+	if p.unread != nil {
+		token := *p.unread
+		p.unread = nil
+		return token, nil
+	}
+	return p.source.Next()
+}
+
+// fail records a syntax error spanning a token
+func (p *parser) fail(token Token, message string) {
+	p.errors = append(p.errors, &SyntaxError{
+		Message:   message,
+		Line:      token.Line,
+		Column:    token.Column,
+		EndLine:   token.Line,
+		EndColumn: token.Column + utf8.RuneCountInString(token.Value),
+	})
+}
+
+// Parse reads tokens and constructs a Lisp expression tree. The error lists
+// every syntax error of the expression.
+func Parse(tokens []Token) (LispValue, []Token, error) {
+	source := &tokenSlice{tokens: tokens}
+	p := &parser{source: source}
+	token, err := p.next()
+	if err != nil {
+		return nil, nil, SyntaxErrors{{Message: "unexpected EOF while reading"}}
+	}
+	result, err := p.expr(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(p.errors) > 0 {
+		return nil, nil, p.errors
+	}
+	if p.unread != nil {
+		return result, append([]Token{*p.unread}, source.tokens...), nil
+	}
+	return result, source.tokens, nil
+}
+
+// expr constructs the expression starting with a token. It only returns the
+// errors of the source, and records the syntax errors.
+func (p *parser) expr(token Token) (LispValue, error) {
+	switch token.Type {
+	case string(OPEN_BRACKET):
+		elements := make([]LispValue, 0, 8)
+		for {
+			next, err := p.next()
+			if err == io.EOF {
+				p.fail(token, "unmatched (: the list is never closed")
+				return &LispNil{}, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			if next.Type == string(CLOSE_BRACKET) {
+				break
+			}
+			elem, err := p.expr(next)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, elem)
+		}
+		return &LispList{Elements: elements, Line: token.Line, Column: token.Column}, nil
+	case string(SINGLE_QUOTE):
+		next, err := p.next()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if err == io.EOF || next.Type == string(CLOSE_BRACKET) {
+			if err == nil {
+				p.unread = &next
+			}
+			p.fail(token, "missing expression after '")
+			return &LispNil{}, nil
+		}
+		quoted, err := p.expr(next)
+		if err != nil {
+			return nil, err
+		}
+		return &LispList{Elements: []LispValue{&LispAtom{Value: QUOTE}, quoted}, Line: token.Line, Column: token.Column}, nil
+	case string(CLOSE_BRACKET):
+		p.fail(token, "unexpected ): no list to close")
+		return &LispNil{}, nil
+	case STRING:
+		return &LispString{Value: token.Value}, nil
+	case NUMBER, RATIONAL, FLOAT, ILLEGAL:
+		result, err := parseNumber(token.Value)
+		if err != nil {
+			p.fail(token, err.Error())
+			return &LispNil{}, nil
+		}
+		return result, nil
+	case BOOLEAN:
+		return &LispBoolean{Value: token.Value == TRUE}, nil
+	case NIL:
+		return &LispNil{}, nil
+	default:
+		return &LispAtom{Value: token.Value, Line: token.Line, Column: token.Column}, nil
+	}
+}
+
+// parse reads the top-level forms of source text, looking them up in the
+// parse cache of the interpreter
+func (interp *Interpreter) parse(src string) ([]LispValue, error) {
+	if forms, ok := interp.parseCache.get(src); ok {
+		return forms, nil
+	}
+	forms, err := NewReader(strings.NewReader(src)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	interp.parseCache.put(src, forms)
+	return forms, nil
+}
@@ -0,0 +1,176 @@
+package lisp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// input returns the current input port, reading stdin unless SetInput changed
+// it. The reading builtins and the debugger share its buffer, so no input is
+// lost between them.
+func (interp *Interpreter) input() *bufio.Reader {
+	if interp.inputPort == nil {
+		interp.inputPort = bufio.NewReader(os.Stdin)
+	}
+	return interp.inputPort
+}
+
+// inputForms returns the reader of the forms of the current input port,
+// created on first use
+func (interp *Interpreter) inputForms() *Reader {
+	if interp.inputReader == nil {
+		interp.inputReader = NewReader(interp.input())
+	}
+	return interp.inputReader
+}
+
+// SetInput makes r the current input port, read by read, read-line,
+// read-char and peek-char
+func (interp *Interpreter) SetInput(r io.Reader) {
+	interp.inputReader = nil
+	if buffered, ok := r.(*bufio.Reader); ok {
+		interp.inputPort = buffered
+		return
+	}
+	interp.inputPort = bufio.NewReader(r)
+}
+
+// output returns the current output port, writing to stdout unless SetOutput
+// changed it
+func (interp *Interpreter) output() io.Writer {
+	if interp.outputPort == nil {
+		return os.Stdout
+	}
+	return interp.outputPort
+}
+
+// SetOutput makes w the current output port, where read prints its prompt and
+// the interactive debugger its restarts
+func (interp *Interpreter) SetOutput(w io.Writer) {
+	interp.outputPort = w
+}
+
+// errorOutput returns the current error port, writing to stderr unless
+// SetErrorOutput changed it
+func (interp *Interpreter) errorOutput() io.Writer {
+	if interp.errorPort == nil {
+		return os.Stderr
+	}
+	return interp.errorPort
+}
+
+// SetErrorOutput makes w the current error port, where warn prints the
+// warnings no handler muffled
+func (interp *Interpreter) SetErrorOutput(w io.Writer) {
+	interp.errorPort = w
+}
+
+// readLine reads a line without its line ending. It returns false at the
+// end of the input.
+func readLine(in *bufio.Reader) (string, bool) {
+	line, err := in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), true
+}
+
+// builtinRead is built-in implementation of read operation. It prints the
+// prompt, if any, to the current output port and parses the next expression
+// of the current input port.
+//
+//	(read [prompt])
+func builtinRead(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) > 1 {
+		return nil, &LispError{Message: "wrong number of arguments to read", Line: 0, Column: 0}
+	}
+	interp := interpreterOf(env)
+	if len(args) == 1 {
+		prompt, err := Eval(env, args[0])
+		if err != nil {
+			return nil, err
+		}
+		if str, ok := prompt.(*LispString); ok {
+			fmt.Fprint(interp.output(), str.Value)
+		} else {
+			fmt.Fprint(interp.output(), prompt)
+		}
+	}
+	expr, err := interp.inputForms().Next()
+	if err == io.EOF {
+		return nil, fmt.Errorf("read: end of input")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// builtinReadLine is built-in implementation of read-line operation. It
+// returns the next line of the current input port, or nil at the end.
+//
+//	(read-line)
+func builtinReadLine(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) != 0 {
+		return nil, &LispError{Message: "wrong number of arguments to read-line", Line: 0, Column: 0}
+	}
+	line, ok := readLine(interpreterOf(env).input())
+	if !ok {
+		return &LispNil{}, nil
+	}
+	return &LispString{Value: line}, nil
+}
+
+// builtinReadChar is built-in implementation of read-char and peek-char
+// operations. They return the next character of the current input port as a
+// string, or nil at the end, and peek-char leaves it in the port.
+//
+//	(read-char)
+//	(peek-char)
+func builtinReadChar(env Environment, args []LispValue, name string) (LispValue, error) {
+	if len(args) != 0 {
+		return nil, &LispError{Message: fmt.Sprintf("wrong number of arguments to %s", name), Line: 0, Column: 0}
+	}
+	in := interpreterOf(env).input()
+	char, _, err := in.ReadRune()
+	if err == io.EOF {
+		return &LispNil{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if name == PEEK_CHAR {
+		in.UnreadRune()
+	}
+	return &LispString{Value: string(char)}, nil
+}
+
+// builtinReadFromString is built-in implementation of read-from-string
+// operation. It parses the first expression of a string.
+//
+//	(read-from-string string)
+func builtinReadFromString(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) != 1 {
+		return nil, &LispError{Message: "wrong number of arguments to read-from-string", Line: 0, Column: 0}
+	}
+	val, err := Eval(env, args[0])
+	if err != nil {
+		return nil, err
+	}
+	str, ok := val.(*LispString)
+	if !ok {
+		return nil, fmt.Errorf("invalid argument to read-from-string: %v is not a string", val)
+	}
+	expr, err := NewReader(strings.NewReader(str.Value)).Next()
+	if err == io.EOF {
+		return nil, fmt.Errorf("read-from-string: end of input")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
@@ -0,0 +1,170 @@
+package lisp
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"time"
+)
+
+// Seed resets the generator state from a seed
+func (r *LispRandomState) Seed(seed int64) {
+	r.State = uint64(seed)
+}
+
+// Uint64 returns the next value of the SplitMix64 sequence
+func (r *LispRandomState) Uint64() uint64 {
+	r.State += 0x9e3779b97f4a7c15
+	z := r.State
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer
+func (r *LispRandomState) Int63() int64 {
+	return int64(r.Uint64() >> 1)
+}
+
+// evalRandomState evaluates the optional random state argument found at
+// position index, falling back to the default generator
+func evalRandomState(env Environment, args []LispValue, index int, name string) (*rand.Rand, error) {
+	interp := interpreterOf(env)
+	if len(args) <= index {
+		return rand.New(interp.randomState), nil
+	}
+	val, err := Eval(env, args[index])
+	if err != nil {
+		return nil, err
+	}
+	state, ok := val.(*LispRandomState)
+	if !ok {
+		return nil, &LispError{Message: fmt.Sprintf("invalid random state argument to %s: %v", name, val), Line: 0, Column: 0}
+	}
+	return rand.New(state), nil
+}
+
+// builtinRandom is built-in implementation of random operation. It returns an
+// integer below an integer limit or a float below a float limit.
+func builtinRandom(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, &LispError{Message: "wrong number of arguments to random", Line: 0, Column: 0}
+	}
+	nums, err := evalNumbers(env, args[:1], RANDOM)
+	if err != nil {
+		return nil, err
+	}
+	rnd, err := evalRandomState(env, args, 1, RANDOM)
+	if err != nil {
+		return nil, err
+	}
+	limit := nums[0]
+	if toFloat(limit) <= 0 {
+		return nil, &LispError{Message: fmt.Sprintf("invalid argument to random: %v is not positive", limit), Line: 0, Column: 0}
+	}
+	switch v := limit.(type) {
+	case *LispNumber:
+		return &LispNumber{Value: int(rnd.Int63n(int64(v.Value)))}, nil
+	case *LispFloat:
+		return &LispFloat{Value: rnd.Float64() * v.Value}, nil
+	}
+	if r := toRat(limit); r.IsInt() {
+		return makeInteger(new(big.Int).Rand(rnd, r.Num())), nil
+	}
+	return nil, &LispError{Message: fmt.Sprintf("invalid argument to random: %v", limit), Line: 0, Column: 0}
+}
+
+// builtinRandomSeed is built-in implementation of random-seed operation. It reseeds the default generator.
+func builtinRandomSeed(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) != 1 {
+		return nil, &LispError{Message: "wrong number of arguments to random-seed", Line: 0, Column: 0}
+	}
+	seed, err := Eval(env, args[0])
+	if err != nil {
+		return nil, err
+	}
+	num, ok := seed.(*LispNumber)
+	if !ok {
+		return nil, &LispError{Message: fmt.Sprintf("invalid argument to random-seed: %v", seed), Line: 0, Column: 0}
+	}
+	interp.randomState.Seed(int64(num.Value))
+	return num, nil
+}
+
+// builtinShuffle is built-in implementation of shuffle operation. It returns a shuffled copy of a list.
+func builtinShuffle(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, &LispError{Message: "wrong number of arguments to shuffle", Line: 0, Column: 0}
+	}
+	val, err := Eval(env, args[0])
+	if err != nil {
+		return nil, err
+	}
+	list, ok := val.(*LispList)
+	if !ok {
+		return nil, fmt.Errorf("invalid argument to shuffle: %v", val)
+	}
+	rnd, err := evalRandomState(env, args, 1, SHUFFLE)
+	if err != nil {
+		return nil, err
+	}
+	elements := append([]LispValue{}, list.Elements...)
+	rnd.Shuffle(len(elements), func(i, j int) {
+		elements[i], elements[j] = elements[j], elements[i]
+	})
+	return &LispList{Elements: elements}, nil
+}
+
+// builtinRandomChoice is built-in implementation of random-choice operation. It picks a random element of a list.
+func builtinRandomChoice(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, &LispError{Message: "wrong number of arguments to random-choice", Line: 0, Column: 0}
+	}
+	val, err := Eval(env, args[0])
+	if err != nil {
+		return nil, err
+	}
+	list, ok := val.(*LispList)
+	if !ok {
+		return nil, fmt.Errorf("invalid argument to random-choice: %v", val)
+	}
+	if len(list.Elements) == 0 {
+		return nil, fmt.Errorf("random-choice of empty list")
+	}
+	rnd, err := evalRandomState(env, args, 1, RANDOM_CHOICE)
+	if err != nil {
+		return nil, err
+	}
+	return list.Elements[rnd.Intn(len(list.Elements))], nil
+}
+
+// builtinMakeRandomState is built-in implementation of make-random-state operation.
+// Without argument it copies the default generator, with a random state it
+// copies that state, with an integer it seeds a new one and with t it seeds
+// a new one from the clock.
+func builtinMakeRandomState(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) > 1 {
+		return nil, &LispError{Message: "wrong number of arguments to make-random-state", Line: 0, Column: 0}
+	}
+	if len(args) == 0 {
+		return &LispRandomState{State: interp.randomState.State}, nil
+	}
+	val, err := Eval(env, args[0])
+	if err != nil {
+		return nil, err
+	}
+	switch v := val.(type) {
+	case *LispRandomState:
+		return &LispRandomState{State: v.State}, nil
+	case *LispNumber:
+		return &LispRandomState{State: uint64(v.Value)}, nil
+	case *LispBoolean:
+		if v.Value {
+			return &LispRandomState{State: uint64(time.Now().UnixNano())}, nil
+		}
+		return &LispRandomState{State: interp.randomState.State}, nil
+	}
+	return nil, &LispError{Message: fmt.Sprintf("invalid argument to make-random-state: %v", val), Line: 0, Column: 0}
+}
@@ -0,0 +1,76 @@
+package lisp
+
+import (
+	"errors"
+	"io"
+)
+
+// Reader reads top-level forms one at a time from the runes of a reader, so
+// forms can be evaluated as they arrive
+type Reader struct {
+	lexer  *Lexer
+	parser *parser
+	// lexErrors is the number of lexical errors already returned
+	lexErrors int
+}
+
+// NewReader returns a reader of the forms of r
+func NewReader(r io.Reader) *Reader {
+	lexer := NewLexer(r)
+	return &Reader{lexer: lexer, parser: &parser{source: lexer}}
+}
+
+// Next returns the next form, or io.EOF once every form was read. It reads
+// no further than the end of the form. A form with syntax errors returns
+// them all as SyntaxErrors, and the next call reads the form after it.
+func (r *Reader) Next() (LispValue, error) {
+	p := r.parser
+	token, err := p.next()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	var form LispValue
+	if err == nil {
+		form, err = p.expr(token)
+		if err != nil {
+			return nil, err
+		}
+	}
+	errs := append(p.errors, r.lexer.errors[r.lexErrors:]...)
+	r.lexErrors = len(r.lexer.errors)
+	p.errors = nil
+	if len(errs) > 0 {
+		sortSyntaxErrors(errs)
+		return nil, errs
+	}
+	if form == nil {
+		return nil, io.EOF
+	}
+	return form, nil
+}
+
+// ReadAll returns the forms left to read. When some of them have syntax
+// errors, it returns every error as SyntaxErrors.
+func (r *Reader) ReadAll() ([]LispValue, error) {
+	var forms []LispValue
+	var syntaxErrors SyntaxErrors
+	for {
+		form, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		var errs SyntaxErrors
+		if errors.As(err, &errs) {
+			syntaxErrors = append(syntaxErrors, errs...)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		forms = append(forms, form)
+	}
+	if len(syntaxErrors) > 0 {
+		return nil, syntaxErrors
+	}
+	return forms, nil
+}
@@ -0,0 +1,294 @@
+package lisp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Keywords of the defstruct options
+const (
+	CONC_NAME_OPTION   = ":conc-name"
+	CONSTRUCTOR_OPTION = ":constructor"
+	PREDICATE_OPTION   = ":predicate"
+	COPIER_OPTION      = ":copier"
+)
+
+// slotIndex returns the index of a slot of a record type, or -1 if it has no such slot
+func (t *LispRecordType) slotIndex(name string) int {
+	for i, slot := range t.Slots {
+		if slot == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// recordArgument checks that an argument of a record function is a record of the given type
+func recordArgument(val LispValue, recordType *LispRecordType, name string) (*LispRecord, error) {
+	record, ok := val.(*LispRecord)
+	if !ok || record.Type != recordType {
+		return nil, fmt.Errorf("invalid argument to %s: %v is not a %s", name, val, recordType.Name)
+	}
+	return record, nil
+}
+
+// defineRecordFunction binds a primitive in the environment
+func defineRecordFunction(env Environment, name string, fn func(args []LispValue) (LispValue, error)) {
+	env[name] = &LispPrimitive{Name: name, Fn: fn}
+}
+
+// recordAccessor returns the function reading a slot of a record
+func recordAccessor(recordType *LispRecordType, index int, name string) func(args []LispValue) (LispValue, error) {
+	return func(args []LispValue) (LispValue, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("wrong number of arguments to %s", name)
+		}
+		record, err := recordArgument(args[0], recordType, name)
+		if err != nil {
+			return nil, err
+		}
+		return record.Values[index], nil
+	}
+}
+
+// recordModifier returns the function changing a slot of a record
+func recordModifier(recordType *LispRecordType, index int, name string) func(args []LispValue) (LispValue, error) {
+	return func(args []LispValue) (LispValue, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("wrong number of arguments to %s", name)
+		}
+		record, err := recordArgument(args[0], recordType, name)
+		if err != nil {
+			return nil, err
+		}
+		record.Values[index] = args[1]
+		return &LispNil{}, nil
+	}
+}
+
+// recordPredicate returns the function checking that a value is a record of a type
+func recordPredicate(recordType *LispRecordType, name string) func(args []LispValue) (LispValue, error) {
+	return func(args []LispValue) (LispValue, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("wrong number of arguments to %s", name)
+		}
+		record, ok := args[0].(*LispRecord)
+		return &LispBoolean{Value: ok && record.Type == recordType}, nil
+	}
+}
+
+// defstructOption reads the name given to a defstruct option. A nil or
+// missing name disables the option.
+func defstructOption(option *LispList) (string, error) {
+	if len(option.Elements) == 1 {
+		return "", nil
+	}
+	switch v := option.Elements[1].(type) {
+	case *LispAtom:
+		return v.Value, nil
+	case *LispNil:
+		return "", nil
+	}
+	return "", fmt.Errorf("invalid defstruct option: %v", option)
+}
+
+// builtinDefstruct is built-in implementation of defstruct. It defines a
+// record type with a keyword constructor, slot accessors, a predicate and a
+// copier. Slot defaults are evaluated each time the constructor omits them.
+//
+//	(defstruct name slot | (slot default)...)
+//	(defstruct (name (:conc-name prefix) (:constructor name) (:predicate name) (:copier name)) slot...)
+func builtinDefstruct(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("wrong number of arguments to defstruct")
+	}
+	var name *LispAtom
+	var options []LispValue
+	switch v := args[0].(type) {
+	case *LispAtom:
+		name = v
+	case *LispList:
+		if len(v.Elements) > 0 {
+			name, _ = v.Elements[0].(*LispAtom)
+			options = v.Elements[1:]
+		}
+	}
+	if name == nil {
+		return nil, fmt.Errorf("invalid defstruct name: %v", args[0])
+	}
+
+	concName := name.Value + "-"
+	constructor := "make-" + name.Value
+	predicate := name.Value + "-p"
+	copier := "copy-" + name.Value
+	for _, option := range options {
+		optionList, ok := option.(*LispList)
+		if !ok || len(optionList.Elements) < 1 || len(optionList.Elements) > 2 {
+			return nil, fmt.Errorf("invalid defstruct option: %v", option)
+		}
+		value, err := defstructOption(optionList)
+		if err != nil {
+			return nil, err
+		}
+		switch optionList.Elements[0].String() {
+		case CONC_NAME_OPTION:
+			concName = value
+		case CONSTRUCTOR_OPTION:
+			constructor = value
+		case PREDICATE_OPTION:
+			predicate = value
+		case COPIER_OPTION:
+			copier = value
+		default:
+			return nil, fmt.Errorf("invalid defstruct option: %v", option)
+		}
+	}
+
+	recordType := &LispRecordType{Name: name.Value}
+	defaults := make([]LispValue, 0, len(args)-1)
+	for _, spec := range args[1:] {
+		switch v := spec.(type) {
+		case *LispAtom:
+			recordType.Slots = append(recordType.Slots, v.Value)
+			defaults = append(defaults, nil)
+		case *LispList:
+			if len(v.Elements) != 2 {
+				return nil, fmt.Errorf("invalid defstruct slot: %v", spec)
+			}
+			slot, ok := v.Elements[0].(*LispAtom)
+			if !ok {
+				return nil, fmt.Errorf("invalid defstruct slot: %v", spec)
+			}
+			recordType.Slots = append(recordType.Slots, slot.Value)
+			defaults = append(defaults, v.Elements[1])
+		default:
+			return nil, fmt.Errorf("invalid defstruct slot: %v", spec)
+		}
+	}
+
+	if constructor != "" {
+		defineRecordFunction(env, constructor, func(args []LispValue) (LispValue, error) {
+			if len(args)%2 != 0 {
+				return nil, fmt.Errorf("odd number of arguments to %s", constructor)
+			}
+			record := &LispRecord{Type: recordType, Values: make([]LispValue, len(recordType.Slots))}
+			for i := 0; i < len(args); i += 2 {
+				key := strings.TrimPrefix(args[i].String(), KEYWORD_PREFIX)
+				index := recordType.slotIndex(key)
+				if index < 0 {
+					return nil, fmt.Errorf("invalid argument to %s: %s has no slot %v", constructor, recordType.Name, args[i])
+				}
+				record.Values[index] = args[i+1]
+			}
+			for i, val := range record.Values {
+				if val != nil {
+					continue
+				}
+				record.Values[i] = &LispNil{}
+				if defaults[i] != nil {
+					val, err := Eval(env, defaults[i])
+					if err != nil {
+						return nil, err
+					}
+					record.Values[i] = val
+				}
+			}
+			return record, nil
+		})
+	}
+	for i, slot := range recordType.Slots {
+		accessor := concName + slot
+		defineRecordFunction(env, accessor, recordAccessor(recordType, i, accessor))
+	}
+	if predicate != "" {
+		defineRecordFunction(env, predicate, recordPredicate(recordType, predicate))
+	}
+	if copier != "" {
+		defineRecordFunction(env, copier, func(args []LispValue) (LispValue, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("wrong number of arguments to %s", copier)
+			}
+			record, err := recordArgument(args[0], recordType, copier)
+			if err != nil {
+				return nil, err
+			}
+			return &LispRecord{Type: recordType, Values: append([]LispValue{}, record.Values...)}, nil
+		})
+	}
+	return name, nil
+}
+
+// builtinDefineRecordType is built-in implementation of define-record-type.
+// It binds the type name to the record type, and defines a positional
+// constructor, a predicate, and an accessor and optional modifier per field.
+// Fields the constructor doesn't take start as nil.
+//
+//	(define-record-type <name> (constructor field...) predicate (field accessor [modifier])...)
+func builtinDefineRecordType(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 3 {
+		return nil, fmt.Errorf("wrong number of arguments to define-record-type")
+	}
+	typeName, ok := args[0].(*LispAtom)
+	if !ok {
+		return nil, fmt.Errorf("invalid record type name: %v", args[0])
+	}
+	constructorSpec, ok := args[1].(*LispList)
+	if !ok || len(constructorSpec.Elements) < 1 {
+		return nil, fmt.Errorf("invalid record constructor: %v", args[1])
+	}
+	predicate, ok := args[2].(*LispAtom)
+	if !ok {
+		return nil, fmt.Errorf("invalid record predicate: %v", args[2])
+	}
+
+	recordType := &LispRecordType{Name: strings.TrimSuffix(strings.TrimPrefix(typeName.Value, "<"), ">")}
+	fieldSpecs := make([][]LispValue, 0, len(args)-3)
+	for _, spec := range args[3:] {
+		specList, ok := spec.(*LispList)
+		if !ok || len(specList.Elements) < 2 || len(specList.Elements) > 3 {
+			return nil, fmt.Errorf("invalid record field: %v", spec)
+		}
+		for _, elem := range specList.Elements {
+			if _, ok := elem.(*LispAtom); !ok {
+				return nil, fmt.Errorf("invalid record field: %v", spec)
+			}
+		}
+		recordType.Slots = append(recordType.Slots, specList.Elements[0].String())
+		fieldSpecs = append(fieldSpecs, specList.Elements)
+	}
+
+	constructor := constructorSpec.Elements[0].String()
+	indexes := make([]int, 0, len(constructorSpec.Elements)-1)
+	for _, field := range constructorSpec.Elements[1:] {
+		index := recordType.slotIndex(field.String())
+		if index < 0 {
+			return nil, fmt.Errorf("invalid record constructor: %s is not a field", field)
+		}
+		indexes = append(indexes, index)
+	}
+
+	env[typeName.Value] = recordType
+	defineRecordFunction(env, constructor, func(args []LispValue) (LispValue, error) {
+		if len(args) != len(indexes) {
+			return nil, fmt.Errorf("wrong number of arguments to %s", constructor)
+		}
+		record := &LispRecord{Type: recordType, Values: make([]LispValue, len(recordType.Slots))}
+		for i := range record.Values {
+			record.Values[i] = &LispNil{}
+		}
+		for i, index := range indexes {
+			record.Values[index] = args[i]
+		}
+		return record, nil
+	})
+	defineRecordFunction(env, predicate.Value, recordPredicate(recordType, predicate.Value))
+	for i, spec := range fieldSpecs {
+		accessor := spec[1].String()
+		defineRecordFunction(env, accessor, recordAccessor(recordType, i, accessor))
+		if len(spec) == 3 {
+			modifier := spec[2].String()
+			defineRecordFunction(env, modifier, recordModifier(recordType, i, modifier))
+		}
+	}
+	return typeName, nil
+}
@@ -0,0 +1,137 @@
+package lisp
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SpecialForm is the Go implementation of a builtin receiving its arguments
+// unevaluated, together with the environment of the call
+type SpecialForm func(env Environment, args []LispValue) (LispValue, error)
+
+// Builtin describes a builtin registered in an interpreter: either a special
+// form or a function called with its evaluated arguments
+type Builtin struct {
+	Name string
+	Doc  string
+	// Arity is the number of arguments of a function, or -1 for any number
+	Arity int
+	// Form implements a special form; it is nil for a function
+	Form SpecialForm
+	// Special marks a form whose arguments aren't evaluated as those of a
+	// function call, such as if or let. Functions bound in the environment
+	// shadow the other builtins, but not the special ones.
+	Special bool
+	// Fn implements a function
+	Fn func(args []LispValue) (LispValue, error)
+	// Unsafe marks a builtin reading stdin or reaching files, processes or
+	// the network, which sandboxed interpreters disable by default
+	Unsafe bool
+}
+
+// call evaluates a call to the builtin
+func (b *Builtin) call(env Environment, args []LispValue) (LispValue, error) {
+	if b.Form != nil {
+		return b.Form(env, args)
+	}
+	if b.Arity >= 0 && len(args) != b.Arity {
+		return nil, &LispError{Message: fmt.Sprintf("wrong number of arguments to %s", b.Name), Line: 0, Column: 0}
+	}
+	vals, err := evalArgs(env, args)
+	if err != nil {
+		return nil, err
+	}
+	return b.Fn(vals)
+}
+
+// withName adapts the implementation of builtins sharing a function, which
+// tells them apart by name, to a special form
+func withName(name string, fn func(env Environment, args []LispValue, name string) (LispValue, error)) SpecialForm {
+	return func(env Environment, args []LispValue) (LispValue, error) {
+		return fn(env, args, name)
+	}
+}
+
+// methodOnly returns the special form of a function only bound inside
+// methods, which calls the function the running method bound
+func methodOnly(name string) SpecialForm {
+	return func(env Environment, args []LispValue) (LispValue, error) {
+		if _, ok := env[name].(*LispPrimitive); !ok {
+			return nil, fmt.Errorf("%s called outside of a method", name)
+		}
+		return callFunction(env, name, args)
+	}
+}
+
+// RegisterFunc registers a Go function as a builtin, replacing any builtin of
+// the same name. The function is called with the evaluated arguments, after
+// checking there are arity of them unless arity is -1. Sandboxed interpreters
+// let programs call it unless MarkUnsafe marks it.
+func (interp *Interpreter) RegisterFunc(name string, fn func(args []LispValue) (LispValue, error), doc string, arity int) {
+	interp.builtins[name] = &Builtin{Name: name, Doc: doc, Arity: arity, Fn: fn}
+}
+
+// RegisterSpecialForm registers a special form as a builtin, replacing any
+// builtin of the same name. Functions bound in the environment don't shadow
+// it. Sandboxed interpreters let programs call it unless MarkUnsafe marks it.
+func (interp *Interpreter) RegisterSpecialForm(name string, form SpecialForm, doc string) {
+	interp.builtins[name] = &Builtin{Name: name, Doc: doc, Arity: -1, Form: form, Special: true}
+}
+
+// MarkUnsafe marks a builtin as reading stdin or reaching files, processes
+// or the network, so sandboxed interpreters disable it unless their Limits
+// allow it explicitly. A host registering such a builtin must mark it.
+func (interp *Interpreter) MarkUnsafe(name string) error {
+	builtin, ok := interp.builtins[name]
+	if !ok {
+		return fmt.Errorf("cannot mark %s unsafe: it is not a builtin", name)
+	}
+	builtin.Unsafe = true
+	return nil
+}
+
+// Unregister removes a builtin
+func (interp *Interpreter) Unregister(name string) {
+	delete(interp.builtins, name)
+}
+
+// Builtins returns the builtins programs can call in the interpreter, sorted
+// by name
+func (interp *Interpreter) Builtins() []Builtin {
+	list := make([]Builtin, 0, len(interp.builtins))
+	for _, builtin := range interp.builtins {
+		if interp.sandbox == nil || interp.sandbox.allows(builtin) {
+			list = append(list, *builtin)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// builtinHelp is built-in implementation of help operation. It returns the
+// description of a builtin, or the names of all the builtins.
+//
+//	(help ['name])
+func builtinHelp(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) > 1 {
+		return nil, &LispError{Message: "wrong number of arguments to help", Line: 0, Column: 0}
+	}
+	interp := interpreterOf(env)
+	if len(args) == 0 {
+		builtins := interp.Builtins()
+		names := make([]LispValue, 0, len(builtins))
+		for _, builtin := range builtins {
+			names = append(names, &LispAtom{Value: builtin.Name})
+		}
+		return &LispList{Elements: names}, nil
+	}
+	name, err := Eval(env, args[0])
+	if err != nil {
+		return nil, err
+	}
+	builtin, ok := interp.builtins[name.String()]
+	if !ok {
+		return nil, fmt.Errorf("invalid argument to help: %v is not a builtin", name)
+	}
+	return &LispString{Value: builtin.Doc}, nil
+}
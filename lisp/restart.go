@@ -0,0 +1,186 @@
+package lisp
+
+import (
+	"fmt"
+)
+
+// restartPoint is a restart established by restart-case or by warn
+type restartPoint struct {
+	Name string
+	// Frame and Clause identify the restart-case form and clause to unwind to
+	Frame  int
+	Clause int
+	Params []LispValue
+	Report string
+}
+
+// restartInvocation unwinds to the form that established an invoked restart
+type restartInvocation struct {
+	Frame  int
+	Clause int
+	Args   []LispValue
+}
+
+// Error returns the error message
+func (e *restartInvocation) Error() string {
+	return "restart invoked outside of its dynamic extent"
+}
+
+func (e *restartInvocation) controlTransfer() {}
+
+// withRestarts evaluates body with a group of restarts established
+func (interp *Interpreter) withRestarts(restarts []restartPoint, body func() (LispValue, error)) (LispValue, error) {
+	saved := interp.restartStack
+	interp.restartStack = append(saved[:len(saved):len(saved)], restarts...)
+	defer func() { interp.restartStack = saved }()
+	return body()
+}
+
+// activeRestarts returns the active restarts, innermost form first, and the
+// restarts of one form in the order of its clauses
+func (interp *Interpreter) activeRestarts() []restartPoint {
+	stack := interp.restartStack
+	restarts := make([]restartPoint, 0, len(stack))
+	for end := len(stack); end > 0; {
+		start := end - 1
+		for start > 0 && stack[start-1].Frame == stack[end-1].Frame {
+			start--
+		}
+		restarts = append(restarts, stack[start:end]...)
+		end = start
+	}
+	return restarts
+}
+
+// findRestart returns the first active restart with a name
+func (interp *Interpreter) findRestart(name string) (restartPoint, bool) {
+	for _, restart := range interp.activeRestarts() {
+		if restart.Name == name {
+			return restart, true
+		}
+	}
+	return restartPoint{}, false
+}
+
+// invokeRestart returns the control transfer to a restart, checking the number of arguments
+func invokeRestart(restart restartPoint, args []LispValue) error {
+	if len(args) != len(restart.Params) {
+		return fmt.Errorf("wrong number of arguments to restart %s", restart.Name)
+	}
+	return &restartInvocation{Frame: restart.Frame, Clause: restart.Clause, Args: args}
+}
+
+// builtinRestartCase is built-in implementation of restart-case. It evaluates
+// an expression with named restarts established. Invoking one of them unwinds
+// to the restart-case and runs its clause with the restart arguments bound to
+// the clause parameters.
+//
+//	(restart-case expression (name (param...) [:report "text"] form...)...)
+func builtinRestartCase(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) < 1 {
+		return nil, fmt.Errorf("wrong number of arguments to restart-case")
+	}
+	frame := interp.newFrame()
+	restarts := make([]restartPoint, 0, len(args)-1)
+	bodies := make([][]LispValue, 0, len(args)-1)
+	for i, clause := range args[1:] {
+		clauseList, ok := clause.(*LispList)
+		if !ok || len(clauseList.Elements) < 2 {
+			return nil, fmt.Errorf("invalid restart-case clause: %v", clause)
+		}
+		name, ok := clauseList.Elements[0].(*LispAtom)
+		if !ok {
+			return nil, fmt.Errorf("invalid restart name: %v", clauseList.Elements[0])
+		}
+		params, ok := clauseList.Elements[1].(*LispList)
+		if !ok {
+			return nil, fmt.Errorf("invalid restart parameters: %v", clauseList.Elements[1])
+		}
+		for _, param := range params.Elements {
+			if _, ok := param.(*LispAtom); !ok {
+				return nil, fmt.Errorf("invalid parameter name: %v", param)
+			}
+		}
+		restart := restartPoint{Name: name.Value, Frame: frame, Clause: i, Params: params.Elements}
+		body := clauseList.Elements[2:]
+		if len(body) >= 2 {
+			if key, ok := body[0].(*LispAtom); ok && key.Value == REPORT_KEYWORD {
+				report, ok := body[1].(*LispString)
+				if !ok {
+					return nil, fmt.Errorf("invalid restart report: %v", body[1])
+				}
+				restart.Report = report.Value
+				body = body[2:]
+			}
+		}
+		restarts = append(restarts, restart)
+		bodies = append(bodies, body)
+	}
+
+	result, err := interp.withRestarts(restarts, func() (LispValue, error) {
+		return Eval(env, args[0])
+	})
+	invocation, ok := err.(*restartInvocation)
+	if !ok || invocation.Frame != frame {
+		return result, err
+	}
+	localEnv := newLocalEnvironment(env)
+	for i, param := range restarts[invocation.Clause].Params {
+		localEnv[param.(*LispAtom).Value] = invocation.Args[i]
+	}
+	return evalBody(localEnv, bodies[invocation.Clause])
+}
+
+// builtinInvokeRestart is built-in implementation of invoke-restart operation.
+// It transfers control to the innermost active restart with the given name.
+func builtinInvokeRestart(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) < 1 {
+		return nil, &LispError{Message: "wrong number of arguments to invoke-restart", Line: 0, Column: 0}
+	}
+	vals, err := evalArgs(env, args)
+	if err != nil {
+		return nil, err
+	}
+	name, ok := vals[0].(*LispAtom)
+	if !ok {
+		return nil, fmt.Errorf("invalid restart name: %v", vals[0])
+	}
+	restart, ok := interp.findRestart(name.Value)
+	if !ok {
+		return nil, fmt.Errorf("no active restart: %s", name.Value)
+	}
+	return nil, invokeRestart(restart, vals[1:])
+}
+
+// builtinComputeRestarts is built-in implementation of compute-restarts operation.
+// It returns the names of the active restarts, innermost first.
+func builtinComputeRestarts(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) != 0 {
+		return nil, &LispError{Message: "wrong number of arguments to compute-restarts", Line: 0, Column: 0}
+	}
+	names := make([]LispValue, 0, len(interp.restartStack))
+	for _, restart := range interp.activeRestarts() {
+		names = append(names, &LispAtom{Value: restart.Name})
+	}
+	return &LispList{Elements: names}, nil
+}
+
+// builtinMuffleWarning is built-in implementation of muffle-warning operation.
+// It invokes the restart established by warn, so the warning is not printed.
+func builtinMuffleWarning(env Environment, args []LispValue) (LispValue, error) {
+	interp := interpreterOf(env)
+	if len(args) > 1 {
+		return nil, &LispError{Message: "wrong number of arguments to muffle-warning", Line: 0, Column: 0}
+	}
+	if _, err := evalArgs(env, args); err != nil {
+		return nil, err
+	}
+	restart, ok := interp.findRestart(MUFFLE_WARNING)
+	if !ok {
+		return nil, fmt.Errorf("no active restart: %s", MUFFLE_WARNING)
+	}
+	return nil, invokeRestart(restart, nil)
+}
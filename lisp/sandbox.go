@@ -0,0 +1,218 @@
+package lisp
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// BIGNUM_STEP_WORDS is the number of multiplications of machine words of an
+// operation on big numbers counting as one evaluation step
+const BIGNUM_STEP_WORDS = 1024
+
+// Names of the limits of a sandbox, reported by LimitError
+const (
+	LIMIT_STEPS    = "max-steps"
+	LIMIT_DEPTH    = "max-depth"
+	LIMIT_ALLOC    = "max-alloc-bytes"
+	LIMIT_BUILTINS = "builtins"
+)
+
+// Limits restricts the evaluations of a sandboxed interpreter. A zero limit
+// doesn't restrict anything. The counters start over on every call to
+// EvalForm, EvalForms, EvalString, EvalReader or EvalFile, and every form
+// the call evaluates counts against the same limits.
+type Limits struct {
+	// MaxSteps is the number of function calls and special forms evaluated.
+	// Arithmetic on big numbers also counts a step per BIGNUM_STEP_WORDS
+	// multiplications of machine words it can take.
+	MaxSteps int
+	// MaxDepth is the nesting depth of the calls being evaluated
+	MaxDepth int
+	// MaxAllocBytes is the size of the lists, strings and big numbers the
+	// builtins return, counting a machine word per list element, a byte per
+	// string byte and the bytes of the digits of a number. Builtins computing
+	// big numbers check the size of their result before computing it.
+	MaxAllocBytes int
+	// Builtins lists the names of the builtins programs can call. When it is
+	// nil, every builtin can be called except the unsafe ones.
+	Builtins []string
+}
+
+// LimitError is returned by an evaluation exceeding a limit of the sandbox.
+// Condition handlers can't catch it.
+type LimitError struct {
+	// Limit is the name of the limit, such as LIMIT_STEPS
+	Limit string
+	// Max is the value of the limit, unused for LIMIT_BUILTINS
+	Max int
+	// Builtin is the builtin called against LIMIT_BUILTINS
+	Builtin string
+}
+
+// Error returns the error message
+func (e *LimitError) Error() string {
+	if e.Limit == LIMIT_BUILTINS {
+		return fmt.Sprintf("sandbox limit exceeded: %s is not allowed", e.Builtin)
+	}
+	return fmt.Sprintf("sandbox limit exceeded: %s (%d)", e.Limit, e.Max)
+}
+
+func (e *LimitError) controlTransfer() {}
+
+// sandbox holds the limits of a sandboxed interpreter and the usage of the
+// running evaluation
+type sandbox struct {
+	limits  Limits
+	allowed map[string]bool
+	steps   int
+	depth   int
+	alloc   int
+	// counted holds the lists and strings already added to alloc
+	counted map[LispValue]bool
+	// runs is the number of evaluation calls in progress, nested ones included
+	runs int
+}
+
+// Sandbox restricts the evaluations of the interpreter to limits. It disables
+// the unsafe builtins, reading stdin or reaching files, processes or the
+// network, unless limits.Builtins allows them explicitly.
+func (interp *Interpreter) Sandbox(limits Limits) {
+	sb := &sandbox{limits: limits}
+	if limits.Builtins != nil {
+		sb.allowed = make(map[string]bool, len(limits.Builtins))
+		for _, name := range limits.Builtins {
+			sb.allowed[name] = true
+		}
+	}
+	interp.sandbox = sb
+}
+
+// allows checks that the sandbox lets programs call a builtin
+func (sb *sandbox) allows(builtin *Builtin) bool {
+	if sb.allowed == nil {
+		return !builtin.Unsafe
+	}
+	return sb.allowed[builtin.Name]
+}
+
+// reset starts counting the usage of a new top-level evaluation
+func (sb *sandbox) reset() {
+	sb.steps, sb.depth, sb.alloc = 0, 0, 0
+	sb.counted = nil
+}
+
+// startRun starts counting the usage of an evaluation call, unless it is
+// nested in another one, and returns the function ending it
+func (interp *Interpreter) startRun() func() {
+	sb := interp.sandbox
+	if sb == nil {
+		return func() {}
+	}
+	if sb.runs == 0 {
+		sb.reset()
+	}
+	sb.runs++
+	return func() {
+		sb.runs--
+		if sb.runs == 0 {
+			sb.reset()
+		}
+	}
+}
+
+// enter counts a call starting, which leave must end
+func (sb *sandbox) enter() error {
+	sb.steps++
+	if sb.limits.MaxSteps > 0 && sb.steps > sb.limits.MaxSteps {
+		return &LimitError{Limit: LIMIT_STEPS, Max: sb.limits.MaxSteps}
+	}
+	sb.depth++
+	if sb.limits.MaxDepth > 0 && sb.depth > sb.limits.MaxDepth {
+		sb.depth--
+		return &LimitError{Limit: LIMIT_DEPTH, Max: sb.limits.MaxDepth}
+	}
+	return nil
+}
+
+// leave counts a call ending
+func (sb *sandbox) leave() {
+	sb.depth--
+}
+
+// allocate counts the size of a list or a string a builtin returned, unless
+// it was counted already
+func (sb *sandbox) allocate(val LispValue) error {
+	if sb.limits.MaxAllocBytes <= 0 {
+		return nil
+	}
+	var size int
+	switch v := val.(type) {
+	case *LispList:
+		size = 8 * len(v.Elements)
+	case *LispString:
+		size = len(v.Value)
+	case *LispRational:
+		size = ratBytes(v.Value)
+	default:
+		return nil
+	}
+	if sb.counted[val] {
+		return nil
+	}
+	if sb.counted == nil {
+		sb.counted = map[LispValue]bool{}
+	}
+	sb.counted[val] = true
+	sb.alloc += size
+	if sb.alloc > sb.limits.MaxAllocBytes {
+		return &LimitError{Limit: LIMIT_ALLOC, Max: sb.limits.MaxAllocBytes}
+	}
+	return nil
+}
+
+// ratBytes returns the size of the digits of a rational number
+func ratBytes(r *big.Rat) int {
+	return (r.Num().BitLen() + r.Denom().BitLen() + 7) / 8
+}
+
+// reserve checks that a builtin can return a big number of bits more bits,
+// before it computes it
+func (interp *Interpreter) reserve(bits int) error {
+	sb := interp.sandbox
+	if sb == nil || sb.limits.MaxAllocBytes <= 0 {
+		return nil
+	}
+	if sb.alloc+bits/8 > sb.limits.MaxAllocBytes {
+		return &LimitError{Limit: LIMIT_ALLOC, Max: sb.limits.MaxAllocBytes}
+	}
+	return nil
+}
+
+// bignumWork checks the context and counts the steps of an operation on big
+// numbers of words machine words, which takes up to words² multiplications
+// of machine words, before it runs
+func (interp *Interpreter) bignumWork(words int) error {
+	if err := interp.checkContext(); err != nil {
+		return err
+	}
+	sb := interp.sandbox
+	if sb == nil || sb.limits.MaxSteps <= 0 {
+		return nil
+	}
+	sb.steps += words / BIGNUM_STEP_WORDS * words
+	if sb.steps > sb.limits.MaxSteps || sb.steps < 0 {
+		return &LimitError{Limit: LIMIT_STEPS, Max: sb.limits.MaxSteps}
+	}
+	return nil
+}
+
+// bigWords returns the number of machine words of the digits of numbers
+func bigWords(nums ...LispValue) int {
+	bits := 0
+	for _, num := range nums {
+		if r, ok := num.(*LispRational); ok {
+			bits += r.Value.Num().BitLen() + r.Value.Denom().BitLen()
+		}
+	}
+	return bits / 64
+}
@@ -0,0 +1,184 @@
+package lisp
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// primaryValue returns the first of multiple values, or nil if there are none.
+// Any other value is returned as it is.
+func primaryValue(val LispValue) LispValue {
+	values, ok := val.(*LispValues)
+	if !ok {
+		return val
+	}
+	if len(values.Values) == 0 {
+		return &LispNil{}
+	}
+	return values.Values[0]
+}
+
+// valuesOf returns every value of a result that may hold multiple values
+func valuesOf(val LispValue) []LispValue {
+	if values, ok := val.(*LispValues); ok {
+		return values.Values
+	}
+	return []LispValue{val}
+}
+
+// bindValues binds variables to values, binding the missing ones to nil and
+// dropping the extra values. The special variables are bound dynamically
+// until unbind restores them.
+func bindValues(env Environment, vars []LispValue, vals []LispValue, name string, restore *[]func()) error {
+	interp := interpreterOf(env)
+	for i, v := range vars {
+		variable, ok := v.(*LispAtom)
+		if !ok {
+			return fmt.Errorf("invalid %s variable: %v", name, v)
+		}
+		var val LispValue = &LispNil{}
+		if i < len(vals) {
+			val = vals[i]
+		}
+		interp.bindVariable(env, variable.Value, val, restore)
+	}
+	return nil
+}
+
+// builtinValues is built-in implementation of values operation. It returns
+// its arguments as multiple values.
+func builtinValues(env Environment, args []LispValue) (LispValue, error) {
+	vals, err := evalArgs(env, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) == 1 {
+		return vals[0], nil
+	}
+	return &LispValues{Values: vals}, nil
+}
+
+// builtinMultipleValueBind is built-in implementation of multiple-value-bind.
+//
+//	(multiple-value-bind (var...) form body...)
+func builtinMultipleValueBind(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("wrong number of arguments to multiple-value-bind")
+	}
+	vars, ok := args[0].(*LispList)
+	if !ok {
+		return nil, fmt.Errorf("invalid multiple-value-bind variables: %v", args[0])
+	}
+	result, err := evalValues(env, args[1])
+	if err != nil {
+		return nil, err
+	}
+	localEnv := newLocalEnvironment(env)
+	var restore []func()
+	defer unbind(&restore)
+	if err := bindValues(localEnv, vars.Elements, valuesOf(result), MULTIPLE_VALUE_BIND, &restore); err != nil {
+		return nil, err
+	}
+	return evalBody(localEnv, args[2:])
+}
+
+// builtinMultipleValueList is built-in implementation of multiple-value-list.
+// It returns the values of a form as a list.
+func builtinMultipleValueList(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments to multiple-value-list")
+	}
+	result, err := evalValues(env, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return &LispList{Elements: append([]LispValue{}, valuesOf(result)...)}, nil
+}
+
+// builtinCallWithValues is built-in implementation of call-with-values. It
+// calls the consumer with the values returned by the producer thunk.
+//
+//	(call-with-values producer consumer)
+func builtinCallWithValues(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("wrong number of arguments to call-with-values")
+	}
+	fns, err := evalArgs(env, args)
+	if err != nil {
+		return nil, err
+	}
+	result, err := applyFunction(fns[0], nil)
+	if err != nil {
+		return nil, err
+	}
+	return applyFunction(fns[1], valuesOf(result))
+}
+
+// builtinReceive is built-in implementation of receive. The formals are a
+// list of variables, each bound to one value, a dotted list whose last
+// variable is bound to the list of the values left, or a single variable
+// bound to the list of all values.
+//
+//	(receive (var...) form body...)
+//	(receive (var... . rest) form body...)
+//	(receive var form body...)
+func builtinReceive(env Environment, args []LispValue) (LispValue, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("wrong number of arguments to receive")
+	}
+	result, err := evalValues(env, args[1])
+	if err != nil {
+		return nil, err
+	}
+	vals := valuesOf(result)
+	localEnv := newLocalEnvironment(env)
+	var restore []func()
+	defer unbind(&restore)
+	switch formals := args[0].(type) {
+	case *LispAtom:
+		interpreterOf(env).bindVariable(localEnv, formals.Value, &LispList{Elements: append([]LispValue{}, vals...)}, &restore)
+	case *LispList:
+		fixed, rest := splitDotted(formals.Elements)
+		if len(fixed) != len(vals) && (rest == nil || len(vals) < len(fixed)) {
+			return nil, fmt.Errorf("receive expected %d values, got %d", len(fixed), len(vals))
+		}
+		if err := bindValues(localEnv, fixed, vals, RECEIVE, &restore); err != nil {
+			return nil, err
+		}
+		if rest != nil {
+			restVar, ok := rest.(*LispAtom)
+			if !ok {
+				return nil, fmt.Errorf("invalid %s variable: %v", RECEIVE, rest)
+			}
+			restVals := &LispList{Elements: append([]LispValue{}, vals[len(fixed):]...)}
+			interpreterOf(env).bindVariable(localEnv, restVar.Value, restVals, &restore)
+		}
+	default:
+		return nil, fmt.Errorf("invalid receive formals: %v", args[0])
+	}
+	return evalBody(localEnv, args[2:])
+}
+
+// builtinDivision is built-in implementation of floor/ and truncate/
+// operations. They return the quotient and the remainder as two values:
+// floor/ rounds the quotient towards negative infinity, so the remainder has
+// the sign of the divisor, and truncate/ rounds it towards zero.
+func builtinDivision(env Environment, args []LispValue, name string) (LispValue, error) {
+	if len(args) != 2 {
+		return nil, &LispError{Message: fmt.Sprintf("wrong number of arguments to %s", name), Line: 0, Column: 0}
+	}
+	ints, err := evalIntegers(env, args, name)
+	if err != nil {
+		return nil, err
+	}
+	a, b := ints[0], ints[1]
+	if b.Sign() == 0 {
+		return nil, fmt.Errorf("division by zero")
+	}
+	q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+	if name == FLOOR_DIV && r.Sign() != 0 && r.Sign() != b.Sign() {
+		q.Sub(q, big.NewInt(1))
+		r.Add(r, b)
+	}
+	return &LispValues{Values: []LispValue{makeInteger(q), makeInteger(r)}}, nil
+}
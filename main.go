@@ -1,150 +1,182 @@
-package main
-
-import (
-	"fmt"
-	"os"
-	"time"
-
-	"github.com/c-bata/go-prompt"
-)
-
-// evalMultipleExpressions evaluates multiple expressions and returns the results
-func evalMultipleExpressions(env Environment, expressions []LispValue) ([]LispValue, error) {
-	results := make([]LispValue, 0, len(expressions))
-	for _, expr := range expressions {
-		result, err := Eval(env, expr)
-		if err != nil {
-			return nil, err
-		}
-		results = append(results, result)
-	}
-	return results, nil
-}
-
-// Environment represents a symbol table
-var env Environment
-
-// completer returns suggestions for the prompt
-func completer(d prompt.Document) []prompt.Suggest {
-	s := []prompt.Suggest{}
-
-	for key, value := range builtins {
-		s = append(s, prompt.Suggest{Text: key, Description: value})
-	}
-
-	// Add defined symbols from the environment
-	for symbol := range env {
-		s = append(s, prompt.Suggest{Text: symbol, Description: "Defined symbol"})
-	}
-
-	return prompt.FilterHasPrefix(s, d.GetWordBeforeCursor(), true)
-}
-
-// executor reads the input, tokenizes it, parses it, and evaluates it
-func executor(input string) {
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Println("Recovered from panic:", r)
-		}
-	}()
-
-	tokens := Tokenize(input)
-	expr, _, err := Parse(tokens)
-	if err != nil {
-		fmt.Println("Error:", err)
-		return
-	}
-	if list, ok := expr.(*LispList); ok {
-		results, err := evalMultipleExpressions(env, list.Elements)
-		if err != nil {
-			fmt.Println("Error:", err)
-		} else {
-			for _, result := range results {
-				fmt.Println(result)
-			}
-		}
-	} else {
-		result, err := Eval(env, expr)
-		if err != nil {
-			fmt.Println("Error:", err)
-		} else {
-			fmt.Println(result)
-		}
-	}
-}
-
-// initEnvironment initializes the environment with predefined symbols
-func initEnvironment() Environment {
-	env := make(Environment)
-	env[T] = &LispBoolean{Value: true}
-	env[NIL] = &LispNil{}
-	env[TRUE] = &LispBoolean{Value: true}
-	env[FALSE] = &LispBoolean{Value: false}
-	return env
-}
-
-// readFile reads the content of a file and returns it as a string
-func readFile(filepath string) (string, error) {
-	data, err := os.ReadFile(filepath)
-	if err != nil {
-		return "", err
-	}
-	return string(data), nil
-}
-
-func main() {
-	env = initEnvironment()
-
-	if len(os.Args) > 1 {
-		// File execution mode
-		filepath := os.Args[1]
-		content, err := readFile(filepath)
-		if err != nil {
-			fmt.Println("Error reading file:", err)
-			return
-		}
-
-		start := time.Now()
-		tokens := Tokenize(content)
-		expr, _, err := Parse(tokens)
-		if err != nil {
-			fmt.Println("Error parsing file:", err)
-			return
-		}
-		results, err := evalMultipleExpressions(env, expr.(*LispList).Elements)
-		if err != nil {
-			fmt.Println("Error evaluating file:", err)
-			return
-		}
-		elapsed := time.Since(start)
-
-		for _, result := range results {
-			fmt.Println(result)
-		}
-		fmt.Printf("\n")
-		fmt.Printf("Execution time: %v\n", elapsed)
-	} else {
-		// REPL mode
-		p := prompt.New(
-			func(input string) {
-				defer func() {
-					if r := recover(); r != nil {
-						fmt.Println("Recovered from panic:", r)
-					}
-				}()
-				executor(input)
-			},
-			completer,
-			prompt.OptionPrefix("cclisp> "),
-			prompt.OptionTitle("CCLisp REPL"),
-			prompt.OptionAddKeyBind(prompt.KeyBind{
-				Key: prompt.ControlC,
-				Fn: func(buf *prompt.Buffer) {
-					fmt.Println("Exiting REPL...")
-					os.Exit(0)
-				},
-			}),
-		)
-		p.Run()
-	}
-}
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+
+	"lisp-interpreter/lisp"
+)
+
+var (
+	// interp is the interpreter the CLI evaluates in
+	interp *lisp.Interpreter
+	// timeout limits the evaluation of a file or of a REPL input, unless it is zero
+	timeout time.Duration
+)
+
+// evalContext returns the context an evaluation runs in
+func evalContext() (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(context.Background(), timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// completer returns suggestions for the prompt
+func completer(d prompt.Document) []prompt.Suggest {
+	s := []prompt.Suggest{}
+
+	for _, builtin := range interp.Builtins() {
+		s = append(s, prompt.Suggest{Text: builtin.Name, Description: builtin.Doc})
+	}
+
+	// Add defined symbols from the environment
+	globals, specials := interp.Symbols()
+	for _, symbol := range globals {
+		s = append(s, prompt.Suggest{Text: symbol, Description: "Defined symbol"})
+	}
+	for _, symbol := range specials {
+		s = append(s, prompt.Suggest{Text: symbol, Description: "Special variable"})
+	}
+
+	return prompt.FilterHasPrefix(s, d.GetWordBeforeCursor(), true)
+}
+
+// executor evaluates the input and prints the results
+func executor(input string) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Println("Recovered from panic:", r)
+		}
+	}()
+
+	ctx, cancel := evalContext()
+	defer cancel()
+	results, err := interp.EvalForms(ctx, input)
+	if errors.Is(err, lisp.ErrAborted) {
+		return
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for _, result := range results {
+		fmt.Println(result)
+	}
+}
+
+// openInput opens the file to evaluate, or stdin for "-". The forms read
+// from stdin share its buffer with read and read-line, so a program can read
+// the data following it.
+func openInput(filepath string) (io.Reader, error) {
+	if filepath == "-" {
+		stdin := bufio.NewReader(os.Stdin)
+		interp.SetInput(stdin)
+		return stdin, nil
+	}
+	return os.Open(filepath)
+}
+
+// checkSyntax prints every syntax error of a file, one per line, and tells
+// whether there are none
+func checkSyntax(filepath string, input io.Reader) bool {
+	_, err := lisp.NewReader(input).ReadAll()
+	var syntaxErrors lisp.SyntaxErrors
+	if errors.As(err, &syntaxErrors) {
+		for _, e := range syntaxErrors {
+			fmt.Printf("%s:%d:%d-%d:%d: %s\n", filepath, e.Line, e.Column, e.EndLine, e.EndColumn, e.Message)
+		}
+		return false
+	}
+	if err != nil {
+		fmt.Println("Error reading file:", err)
+		return false
+	}
+	return true
+}
+
+func main() {
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed for the random number generator")
+	flag.DurationVar(&timeout, "timeout", 0, "maximum evaluation time of a file or a REPL input, such as 5s (0 for no limit)")
+	check := flag.Bool("check", false, "report every syntax error of the file without evaluating it")
+	flag.Parse()
+
+	interp = lisp.NewInterpreter()
+	interp.Seed(*seed)
+
+	if flag.NArg() > 0 {
+		// File execution mode, evaluating each form as soon as it is read
+		filepath := flag.Arg(0)
+		input, err := openInput(filepath)
+		if err != nil {
+			fmt.Println("Error reading file:", err)
+			return
+		}
+		if file, ok := input.(*os.File); ok {
+			defer file.Close()
+		}
+		if *check {
+			if !checkSyntax(filepath, input) {
+				os.Exit(1)
+			}
+			return
+		}
+
+		start := time.Now()
+		ctx, cancel := evalContext()
+		defer cancel()
+		reader := lisp.NewReader(input)
+		for {
+			form, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				fmt.Println("Error reading file:", err)
+				return
+			}
+			result, err := interp.EvalForm(ctx, form)
+			if err != nil {
+				fmt.Println("Error evaluating file:", err)
+				return
+			}
+			fmt.Println(result)
+		}
+		elapsed := time.Since(start)
+
+		fmt.Printf("\n")
+		fmt.Printf("Execution time: %v\n", elapsed)
+	} else {
+		// REPL mode
+		interp.Debugger = interp.InteractiveDebugger
+		p := prompt.New(
+			func(input string) {
+				defer func() {
+					if r := recover(); r != nil {
+						fmt.Println("Recovered from panic:", r)
+					}
+				}()
+				executor(input)
+			},
+			completer,
+			prompt.OptionPrefix("cclisp> "),
+			prompt.OptionTitle("CCLisp REPL"),
+			prompt.OptionAddKeyBind(prompt.KeyBind{
+				Key: prompt.ControlC,
+				Fn: func(buf *prompt.Buffer) {
+					fmt.Println("Exiting REPL...")
+					os.Exit(0)
+				},
+			}),
+		)
+		p.Run()
+	}
+}